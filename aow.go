@@ -18,19 +18,34 @@ var (
 
 // Generator is the structure that manages the settings for the generator.
 type Generator struct {
-	prng          PRNG
-	typeOfCatalog Catalog_e // the type of catalog used to generate the star systems
-	pm            PopulationModel_t
-	Radius        float64 // the radius of the map in parsecs
+	prng                PRNG
+	typeOfCatalog       Catalog_e // the type of catalog used to generate the star systems
+	pm                  PopulationModel_t
+	offset              *galacticOffset_t // optional offset from the center of the galaxy
+	interest            InterestPredicate // systems kept in a reference catalog
+	earthLike           bool              // when set, n is the target number of systems with Earth-like planets
+	target              int               // the target number of systems
+	tables              *Tables_t         // the tables used to generate the catalogs
+	seed                uint64            // the seed that all catalogs are derived from
+	clusters            uint64            // the number of clusters created
+	associations        uint64            // the number of stellar associations created
+	openClusters        *int              // when set, the number of open clusters to seed in the map
+	stellarAssociations *int              // when set, the number of stellar associations to seed in the map
+	nameStyle           string            // when set, the style used to name the star systems
+	Radius              float64           // the radius of the map in parsecs
 
-	Catalog *Catalog_t
+	Catalog      *Catalog_t
+	Clusters     []*Cluster_t     // the clusters created, in order
+	Associations []*Association_t // the stellar associations created, in order
 }
 
 // New returns a new Generator that will generate a catalog with approximately
 // the given number of stellar systems. You may provide additional options to
 // modify the behavior of the generator.
 //
-// The n parameter is the target number of systems with Earth-like planets to generate.
+// The n parameter is the target number of star systems to generate.
+// If the WithEarthLikeSystems option is given, it is the target number of systems
+// with Earth-like planets to generate.
 //
 // The prng parameter is used to generate random numbers.
 // For normal use, pass in a source like rand.NewPCG(rand.Uint64(), rand.Uint64()).
@@ -40,7 +55,11 @@ func New(n int, prng rand.Source, cat Catalog_e, options ...Option) (*Generator,
 		return nil, ErrPRNGNil
 	}
 	g := &Generator{
-		prng: PRNG{Rand: rand.New(prng)},
+		prng:          NewPRNG(prng),
+		typeOfCatalog: cat,
+		interest:      DefaultInterest,
+		tables:        builtinTables(),
+		target:        n,
 	}
 	for _, option := range options {
 		if err := option(g); err != nil {
@@ -48,37 +67,204 @@ func New(n int, prng rand.Source, cat Catalog_e, options ...Option) (*Generator,
 		}
 	}
 
-	// this iteration of the generator uses the basic population model and
-	// treats "n" as the number of Earth-like planets to target.
-	g.pm = PopulationModelForSolLikeNeighborhood(n, 0)
+	// derive all the catalogs from a single seed
+	g.seed = g.prng.WithLabel("generator seed").Uint64()
+
+	// if the caller gave us an offset, we use the advanced population model to
+	// adjust the densities for the neighborhood. otherwise, we use the basic model.
+	switch {
+	case g.earthLike && g.offset != nil:
+		// convert the volume for a Sol-like neighborhood into a number of systems,
+		// then find the volume that holds that many systems in this neighborhood.
+		pm := populationModelForEarthLikeSystems(g.tables.BasicPopulationModel, n, 0)
+		systems := int(math.Ceil(pm.Volume * pm.CombinedDensity))
+		g.pm = PopulationModelForOtherNeighborhoods(systems, g.offset.r, g.offset.h, 0)
+	case g.earthLike:
+		g.pm = populationModelForEarthLikeSystems(g.tables.BasicPopulationModel, n, 0)
+	case g.offset != nil:
+		g.pm = PopulationModelForOtherNeighborhoods(n, g.offset.r, g.offset.h, 0)
+	default:
+		g.pm = populationModelForSolLikeNeighborhood(g.tables.BasicPopulationModel, n, 0)
+	}
 	g.Radius = math.Ceil(math.Cbrt((3 * g.pm.Volume) / (4 * math.Pi)))
 
 	return g, nil
 }
 
+// Seed returns the seed that all the generator's catalogs are derived from.
+func (g *Generator) Seed() uint64 {
+	return g.seed
+}
+
+// PopulationModel returns the population model chosen by the generator.
+func (g *Generator) PopulationModel() PopulationModel_t {
+	return g.pm
+}
+
 // BackgroundPopulation creates the background population of the catalog.
+//
+// It also seeds the map with open clusters and stellar associations and merges the
+// members that fall inside the map into the catalog. See WithOpenClusters and
+// WithStellarAssociations to change the number seeded.
 func (g *Generator) BackgroundPopulation() error {
 	log.Printf("pm %+v\n", g.pm)
-	catalog, err := NewBackgroundPopulation(g.pm, g.prng)
+	prng := g.prng.derive(DeriveSeed(g.seed, seedKeyBackground))
+	catalog, err := NewBackgroundPopulation(g.pm, prng)
 	if err != nil {
 		return err
 	}
-	g.Catalog = catalog
+	if g.earthLike {
+		if err := g.expandUntilEarthLike(catalog, prng); err != nil {
+			return err
+		}
+	}
+	if err := g.seedClustersAndAssociations(catalog); err != nil {
+		return err
+	}
+	g.Catalog = g.applyCatalogType(catalog)
+	if g.nameStyle != "" {
+		return g.Catalog.NameSystems(g.nameStyle)
+	}
 	return nil
 }
 
-// OpenCluster creates a new open cluster.
-func (g *Generator) OpenCluster(origin Coordinates) (*Catalog_t, error) {
-	catalog, err := NewOpenCluster(g.prng)
+// the number of open clusters and stellar associations (per cubic parsec) near Sol
+const (
+	openClusterDensity        = 0.000_01
+	stellarAssociationDensity = 0.000_002_5
+)
+
+// maxOpenClusterHeight is the distance (in parsecs) above or below the galactic plane
+// beyond which there are no open clusters.
+const maxOpenClusterHeight = 360
+
+// seedClustersAndAssociations decides how many open clusters and stellar associations
+// are in the map, places each at a random point in the map, and merges the members
+// that are inside the map into the catalog.
+//
+// Clusters and associations are young disc objects, so their density scales with the
+// density of young population I stars in the neighborhood.
+func (g *Generator) seedClustersAndAssociations(catalog *Catalog_t) error {
+	prng := g.prng.derive(DeriveSeed(g.seed, seedKeyPlacement))
+
+	scale := g.pm.YoungPopulationI.Density / BasicPopulationModelTable().YoungPopulationI.Density
+	clusterDensity := openClusterDensity * scale
+	if g.offset != nil && g.offset.h > maxOpenClusterHeight {
+		clusterDensity = 0
+	}
+	clusters := expectedCount(g.openClusters, clusterDensity*g.pm.Volume, prng.WithLabel("number of open clusters"))
+	associations := expectedCount(g.stellarAssociations, stellarAssociationDensity*scale*g.pm.Volume, prng.WithLabel("number of stellar associations"))
+	log.Printf("seeding %d open clusters and %d stellar associations\n", clusters, associations)
+
+	for n := 0; n < clusters; n++ {
+		cluster, err := g.OpenCluster(prng.WithLabel("cluster placement").GenXYZ().Scale(g.pm.Radius))
+		if err != nil {
+			return err
+		}
+		catalog.mergeWithin(cluster.Catalog, cluster.Center, g.pm.Radius)
+	}
+	for n := 0; n < associations; n++ {
+		association, err := g.StellarAssociation(prng.WithLabel("association placement").GenXYZ().Scale(g.pm.Radius))
+		if err != nil {
+			return err
+		}
+		catalog.mergeWithin(association.Catalog, association.Center, g.pm.Radius)
+	}
+
+	return nil
+}
+
+// expectedCount returns the forced count if it is set. Otherwise, it returns the whole
+// part of the expected number plus one more with a chance equal to the fractional part.
+func expectedCount(forced *int, expected float64, prng PRNG) int {
+	if forced != nil {
+		return *forced
+	}
+	n := math.Floor(expected)
+	if prng.RollPercentile() < expected-n {
+		n++
+	}
+	return int(n)
+}
+
+// maxEarthLikeExpansions is the number of times the generator will expand the
+// volume of the catalog while looking for Earth-like systems.
+const maxEarthLikeExpansions = 16
+
+// expandUntilEarthLike grows the volume of the catalog, adding a shell of new star
+// systems each time, until the catalog holds the target number of systems with
+// Earth-like planets. Systems already in the catalog are not changed.
+func (g *Generator) expandUntilEarthLike(catalog *Catalog_t, prng PRNG) error {
+	for expansions := 0; ; expansions++ {
+		found := catalog.CountEarthLikeSystems()
+		if found >= g.target {
+			return nil
+		} else if expansions == maxEarthLikeExpansions {
+			return ErrEarthLikeTargetNotMet
+		}
+
+		// grow the volume in proportion to the shortfall, but always by at least 10%
+		factor := 2.0
+		if found > 0 {
+			factor = math.Max(float64(g.target)/float64(found), 1.1)
+		}
+		// the shell starts at the radius the catalog already covers, which may have been
+		// rounded up, and grows the volume of that sphere by the factor.
+		innerRadius := g.pm.Radius
+		g.pm.Radius = innerRadius * math.Cbrt(factor)
+		g.pm.Volume = (4 * math.Pi * g.pm.Radius * g.pm.Radius * g.pm.Radius) / 3
+		g.Radius = math.Ceil(g.pm.Radius)
+		log.Printf("earth-like: found %d/%d, expanding radius from %f to %f\n", found, g.target, innerRadius, g.pm.Radius)
+
+		if err := catalog.AddBackgroundShell(g.pm, innerRadius, prng); err != nil {
+			return err
+		}
+	}
+}
+
+// OpenCluster creates a new open cluster centered on the origin and adds it to the
+// generator's list of clusters. The cluster is not merged into the catalog.
+//
+// Each cluster is derived from its own seed, so adding a cluster doesn't change
+// the background population or any other cluster.
+func (g *Generator) OpenCluster(origin Coordinates) (*Cluster_t, error) {
+	g.clusters++
+	cluster, err := newOpenCluster(g.tables, g.prng.derive(DeriveSeed(g.seed, seedKeyOpenCluster, g.clusters)))
+	if err != nil {
+		return nil, err
+	}
+	cluster.setID(int(g.clusters))
+	cluster.MoveTo(origin)
+	g.applyCatalogType(cluster.Catalog)
+	cluster.recount()
+	g.Clusters = append(g.Clusters, cluster)
+	return cluster, nil
+}
+
+// StellarAssociation creates a new stellar association centered on the origin and adds
+// it to the generator's list of associations. The association is not merged into the catalog.
+func (g *Generator) StellarAssociation(origin Coordinates) (*Association_t, error) {
+	g.associations++
+	association, err := newStellarAssociation(g.tables, g.prng.derive(DeriveSeed(g.seed, seedKeyStellarAssociation, g.associations)))
 	if err != nil {
 		return nil, err
 	}
-	return catalog, nil
+	association.setID(int(g.associations))
+	association.MoveTo(origin)
+	g.applyCatalogType(association.Catalog)
+	association.remeasure()
+	g.Associations = append(g.Associations, association)
+	return association, nil
 }
 
-// StellarAssociation creates a new stellar association.
-func (g *Generator) StellarAssociation(origin Coordinates) (*Catalog_t, error) {
-	return g.OpenCluster(origin)
+// applyCatalogType sets the kind of the catalog and, for reference catalogs,
+// removes every star system that isn't interesting.
+func (g *Generator) applyCatalogType(catalog *Catalog_t) *Catalog_t {
+	catalog.Kind = g.typeOfCatalog
+	if catalog.Kind == ReferenceCatalog {
+		catalog.Filter(g.interest)
+	}
+	return catalog
 }
 
 // SortCatalog sorts the catalog by age and population.
@@ -96,8 +282,16 @@ func (g *Generator) GenZonedXYZ(minPct, maxPct float64) Coordinates {
 	return g.prng.GenZonedXYZ(minPct, maxPct).Scale(g.Radius)
 }
 
+// galacticOffset_t is the location of the neighborhood within the galaxy.
+type galacticOffset_t struct {
+	r float64 // distance (in parsecs) from the center of the galaxy
+	h float64 // distance (in parsecs) above or below the galactic plane
+}
+
 type Coordinates struct {
-	X, Y, Z float64
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+	Z float64 `json:"z"`
 }
 
 func (c Coordinates) DistanceBetween(o Coordinates) float64 {
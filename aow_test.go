@@ -0,0 +1,221 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow_test
+
+import (
+	"github.com/mdhender/aow"
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestNew_SolLikeNeighborhood(t *testing.T) {
+	g, err := aow.New(100, rand.NewPCG(0xcafe, 0xcafe), aow.SurveyCatalog)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	expected := aow.PopulationModelForSolLikeNeighborhood(100, 0)
+	result := g.PopulationModel()
+	if math.Abs(result.Volume-expected.Volume) > 0.001 {
+		t.Errorf("New() volume = %f, want %f", result.Volume, expected.Volume)
+	}
+	if result.YoungPopulationI.Density != aow.BasicPopulationModelTable().YoungPopulationI.Density {
+		t.Errorf("New() young population I density = %f, want %f", result.YoungPopulationI.Density, aow.BasicPopulationModelTable().YoungPopulationI.Density)
+	}
+}
+
+func TestNew_WithOffset(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		r, h float64
+	}{
+		{"core", 2_000, 0},
+		{"above disc", 8_000, 800},
+		{"below disc", 8_000, -800},
+	} {
+		g, err := aow.New(100, rand.NewPCG(0xcafe, 0xcafe), aow.SurveyCatalog, aow.WithOffset(tc.r, tc.h))
+		if err != nil {
+			t.Fatalf("%s: New() error = %v", tc.name, err)
+		}
+		expected := aow.PopulationModelForOtherNeighborhoods(100, tc.r, tc.h, 0)
+		result := g.PopulationModel()
+		if math.Abs(result.Volume-expected.Volume) > 0.001 {
+			t.Errorf("%s: New() volume = %f, want %f", tc.name, result.Volume, expected.Volume)
+		}
+		if math.Abs(result.CombinedDensity-expected.CombinedDensity) > 0.000_001 {
+			t.Errorf("%s: New() combined density = %f, want %f", tc.name, result.CombinedDensity, expected.CombinedDensity)
+		}
+		if result.CombinedDensity == aow.BasicPopulationModelTable().CombinedDensity {
+			t.Errorf("%s: New() used the basic population model", tc.name)
+		}
+	}
+}
+
+func TestNew_WithOffsetErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		r, h     float64
+		expected error
+	}{
+		{"too close to core", 299, 0, aow.ErrNeighborhoodOffsetTooSmall},
+		{"too far from core", 30_001, 0, aow.ErrNeighborhoodOffsetTooLarge},
+		{"too far from disc", 8_000, 1_251, aow.ErrNeighborhoodOffsetTooLarge},
+	} {
+		_, err := aow.New(100, rand.NewPCG(0xcafe, 0xcafe), aow.SurveyCatalog, aow.WithOffset(tc.r, tc.h))
+		if err != tc.expected {
+			t.Errorf("%s: New() error = %v, want %v", tc.name, err, tc.expected)
+		}
+	}
+}
+
+func TestNew_WithEarthLikeSystems(t *testing.T) {
+	g, err := aow.New(5, rand.NewPCG(0xcafe, 0xcafe), aow.SurveyCatalog, aow.WithEarthLikeSystems())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	expected := aow.PopulationModelForEarthLikeSystems(5, 0)
+	if result := g.PopulationModel(); math.Abs(result.Volume-expected.Volume) > 0.001 {
+		t.Errorf("New() volume = %f, want %f", result.Volume, expected.Volume)
+	}
+	if err := g.BackgroundPopulation(); err != nil {
+		t.Fatalf("BackgroundPopulation() error = %v", err)
+	}
+	if n := g.Catalog.CountEarthLikeSystems(); n < 5 {
+		t.Errorf("BackgroundPopulation() generated %d Earth-like systems, want at least 5", n)
+	}
+	if g.PopulationModel().Volume < expected.Volume {
+		t.Errorf("BackgroundPopulation() volume = %f, want at least %f", g.PopulationModel().Volume, expected.Volume)
+	}
+	for _, ss := range g.Catalog.StarSystems {
+		if d := ss.Coordinates.DistanceTo(aow.Coordinates{}); d > g.PopulationModel().Radius {
+			t.Errorf("BackgroundPopulation() system at distance %f outside radius %f", d, g.PopulationModel().Radius)
+		}
+	}
+}
+
+func TestNew_WithEarthLikeSystemsAndOffset(t *testing.T) {
+	g, err := aow.New(5, rand.NewPCG(0xcafe, 0xcafe), aow.ReferenceCatalog, aow.WithEarthLikeSystems(), aow.WithOffset(8_000, 800))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if sol := aow.PopulationModelForEarthLikeSystems(5, 0); g.PopulationModel().Volume <= sol.Volume {
+		t.Errorf("New() volume = %f, want more than %f for a sparse neighborhood", g.PopulationModel().Volume, sol.Volume)
+	}
+	if err := g.BackgroundPopulation(); err != nil {
+		t.Fatalf("BackgroundPopulation() error = %v", err)
+	}
+	if n := g.Catalog.CountEarthLikeSystems(); n < 5 {
+		t.Errorf("BackgroundPopulation() generated %d Earth-like systems, want at least 5", n)
+	}
+}
+
+func TestNew_SeedsClustersAndAssociations(t *testing.T) {
+	g, err := aow.New(100, rand.NewPCG(0xcafe, 0xcafe), aow.SurveyCatalog, aow.WithOpenClusters(2), aow.WithStellarAssociations(1))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := g.BackgroundPopulation(); err != nil {
+		t.Fatalf("BackgroundPopulation() error = %v", err)
+	}
+	if len(g.Clusters) != 2 || len(g.Associations) != 1 {
+		t.Fatalf("BackgroundPopulation() seeded %d clusters and %d associations, want 2 and 1", len(g.Clusters), len(g.Associations))
+	}
+	clusters, associations := make(map[int]int), make(map[int]int)
+	for _, ss := range g.Catalog.StarSystems {
+		if ss.Cluster != 0 {
+			clusters[ss.Cluster]++
+		}
+		if ss.Association != 0 {
+			associations[ss.Association]++
+		}
+		if d := ss.Coordinates.DistanceTo(aow.Coordinates{}); d > g.PopulationModel().Radius {
+			t.Errorf("BackgroundPopulation() system at distance %f outside radius %f", d, g.PopulationModel().Radius)
+		}
+	}
+	for _, cluster := range g.Clusters {
+		if clusters[cluster.ID] == 0 && cluster.Catalog.Length() != 0 && cluster.Center.DistanceTo(aow.Coordinates{})+cluster.Radius*0.05 < g.PopulationModel().Radius {
+			t.Errorf("BackgroundPopulation() merged no members of cluster %d", cluster.ID)
+		}
+	}
+	if len(associations) > 1 {
+		t.Errorf("BackgroundPopulation() merged members of %d associations, want 1", len(associations))
+	}
+}
+
+func TestNew_ClustersAndAssociations(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		options []aow.Option
+	}{
+		{"disabled", []aow.Option{aow.WithOpenClusters(0), aow.WithStellarAssociations(0)}},
+		{"far above the disc", []aow.Option{aow.WithOffset(8_000, 1_200)}},
+	} {
+		g, err := aow.New(100, rand.NewPCG(0xcafe, 0xcafe), aow.SurveyCatalog, tc.options...)
+		if err != nil {
+			t.Fatalf("%s: New() error = %v", tc.name, err)
+		}
+		if err := g.BackgroundPopulation(); err != nil {
+			t.Fatalf("%s: BackgroundPopulation() error = %v", tc.name, err)
+		}
+		if len(g.Clusters) != 0 {
+			t.Errorf("%s: BackgroundPopulation() seeded %d clusters, want 0", tc.name, len(g.Clusters))
+		}
+		for _, ss := range g.Catalog.StarSystems {
+			if ss.InCluster || (tc.name == "disabled" && ss.InAssociation) {
+				t.Errorf("%s: BackgroundPopulation() merged a cluster or association member", tc.name)
+				break
+			}
+		}
+	}
+
+	for _, option := range []aow.Option{aow.WithOpenClusters(-1), aow.WithStellarAssociations(-1)} {
+		if _, err := aow.New(100, rand.NewPCG(0xcafe, 0xcafe), aow.SurveyCatalog, option); err != aow.ErrNegativeCount {
+			t.Errorf("New() error = %v, want %v", err, aow.ErrNegativeCount)
+		}
+	}
+}
+
+func TestNew_WithEarthLikeSystemsExpands(t *testing.T) {
+	// thin out the neighborhood so the first pass comes up a little short
+	for _, scale := range []float64{0.45, 0.5, 0.55, 0.6} {
+		tables := aow.DefaultTables()
+		for _, population := range []*float64{
+			&tables.BasicPopulationModel.YoungPopulationI.Density,
+			&tables.BasicPopulationModel.IntermediatePopulationI.Density,
+			&tables.BasicPopulationModel.OldPopulationI.Density,
+			&tables.BasicPopulationModel.DiskPopulationII.Density,
+			&tables.BasicPopulationModel.HaloPopulationII.Density,
+		} {
+			*population *= scale
+		}
+		g, err := aow.New(40, rand.NewPCG(0xcafe, 0xcafe), aow.SurveyCatalog, aow.WithEarthLikeSystems(), aow.WithTables(tables), aow.WithOpenClusters(0), aow.WithStellarAssociations(0))
+		if err != nil {
+			t.Fatalf("%v: New() error = %v", scale, err)
+		}
+		radius := g.PopulationModel().Radius
+		if err := g.BackgroundPopulation(); err != nil {
+			t.Fatalf("%v: BackgroundPopulation() error = %v", scale, err)
+		}
+		pm := g.PopulationModel()
+		if pm.Radius <= radius {
+			t.Errorf("%v: BackgroundPopulation() radius = %f, want more than %f", scale, pm.Radius, radius)
+		}
+		if math.Abs(pm.Volume-4*math.Pi*pm.Radius*pm.Radius*pm.Radius/3) > 0.001 {
+			t.Errorf("%v: BackgroundPopulation() volume %f doesn't match radius %f", scale, pm.Volume, pm.Radius)
+		}
+		if g.Catalog.Radius != pm.Radius {
+			t.Errorf("%v: BackgroundPopulation() catalog radius = %f, want %f", scale, g.Catalog.Radius, pm.Radius)
+		}
+		shell := false
+		for _, ss := range g.Catalog.StarSystems {
+			d := ss.Coordinates.DistanceTo(aow.Coordinates{})
+			if d > pm.Radius {
+				t.Fatalf("%v: BackgroundPopulation() system at distance %f outside radius %f", scale, d, pm.Radius)
+			}
+			// the first pass fills the initial radius; the shells added later start outside it
+			if shell = shell || d > radius; shell && d < radius {
+				t.Fatalf("%v: BackgroundPopulation() shell system %s at distance %f inside the initial radius %f", scale, ss.Designation, d, radius)
+			}
+		}
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow
+
+import (
+	"fmt"
+	"math"
+)
+
+// Association_t is a stellar association: a young, loose group of star systems that
+// formed together but were never bound by their mutual gravity. The members drift
+// away from the region where they formed, so an association grows larger and more
+// diffuse as it ages until it can't be told apart from the background population.
+type Association_t struct {
+	ID                int               // the number of the association in the generator; 0 if it was created on its own
+	Kind              AssociationKind_e // OB or T association
+	Age               float64           // in billions of years
+	InitialRadius     float64           // radius of the region the members formed in, in parsecs
+	ExpansionVelocity float64           // the typical speed (in km/s) of the members away from the center
+	Radius            float64           // distance from the center to the farthest member (at least the initial radius), in parsecs
+	Center            Coordinates       // relative to the center of the map
+	Catalog           *Catalog_t        // the members, with coordinates relative to the center of the association
+}
+
+// AssociationKind_e is the kind of a stellar association.
+type AssociationKind_e int
+
+const (
+	// TAssociation is a group of young, low-mass T Tauri stars.
+	TAssociation AssociationKind_e = iota
+	// OBAssociation is a larger group that includes hot, massive O and B stars.
+	OBAssociation
+)
+
+// String implements the Stringer interface.
+func (k AssociationKind_e) String() string {
+	switch k {
+	case TAssociation:
+		return "T"
+	case OBAssociation:
+		return "OB"
+	}
+	return fmt.Sprintf("AssociationKind_e(%d)", int(k))
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (k AssociationKind_e) MarshalText() ([]byte, error) {
+	switch k {
+	case TAssociation, OBAssociation:
+		return []byte(k.String()), nil
+	}
+	return nil, fmt.Errorf("%d: %w", int(k), ErrInvalidTable)
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (k *AssociationKind_e) UnmarshalText(text []byte) error {
+	for _, kind := range []AssociationKind_e{TAssociation, OBAssociation} {
+		if string(text) == kind.String() {
+			*k = kind
+			return nil
+		}
+	}
+	return fmt.Errorf("association kind %q: %w", string(text), ErrInvalidTable)
+}
+
+// AssociationRules_t is the outcome of the stellar association table.
+type AssociationRules_t struct {
+	Kind         AssociationKind_e `json:"kind"`
+	Age          Range_t           `json:"age"`          // in billions of years
+	Radius       *Dice_t           `json:"radius"`       // initial radius, in parsecs
+	Members      *Dice_t           `json:"members"`      // number of star systems
+	Velocity     float64           `json:"velocity"`     // typical expansion velocity, in km/s
+	MassModifier int               `json:"massModifier"` // added to the stellar mass roll of every primary
+}
+
+// parsecsPerGyrPerKmPerSec converts a speed in km/s to parsecs per billion years.
+const parsecsPerGyrPerKmPerSec = 1_022.7
+
+// NewStellarAssociation creates a stellar association centered on the origin, using the
+// tables from the book. The first draw from the PRNG is the seed of the association's catalog.
+//
+// Each member forms at a random point inside the initial radius and then drifts away
+// from the center in a random direction. Its speed is the expansion velocity of the
+// association scaled by 3d6/10.5, so after t billion years the member has moved
+// speed * t * 1,022.7 parsecs.
+func NewStellarAssociation(prng PRNG) (*Association_t, error) {
+	return newStellarAssociation(builtinTables(), prng)
+}
+
+// newStellarAssociation implements NewStellarAssociation with the given tables.
+func newStellarAssociation(tables *Tables_t, prng PRNG) (*Association_t, error) {
+	seed := prng.WithLabel("catalog seed").Uint64()
+
+	rules := tables.StellarAssociation.Roll(prng.WithLabel("association type"), 0)
+	a := &Association_t{
+		Kind:              rules.Kind,
+		Age:               rules.Age.Place(prng.WithLabel("association age").RollPercentile()),
+		InitialRadius:     float64(rules.Radius.Roll(prng.WithLabel("association radius"))),
+		ExpansionVelocity: rules.Velocity,
+	}
+	a.Catalog = &Catalog_t{Seed: seed}
+
+	numberOfStarSystems := rules.Members.Roll(prng.WithLabel("association size"))
+	for n := 0; n < numberOfStarSystems; n++ {
+		ss := &StarSystem_t{
+			Population: YoungPopulationI,
+			// members formed at different times over the life of the association
+			Age:           a.Age * (0.5 + 0.5*prng.WithLabel("system age").RollPercentile()),
+			InAssociation: true,
+			MassModifier:  rules.MassModifier,
+		}
+
+		// the member formed somewhere in the initial volume and has been drifting outwards since
+		birthplace := prng.WithLabel("system coordinates").GenXYZ().Scale(a.InitialRadius)
+		direction := prng.WithLabel("expansion velocity").GenZonedXYZ(1, 1)
+		speed := a.ExpansionVelocity * prng.WithLabel("expansion velocity").RollD6(3) / 10.5
+		ss.Coordinates = birthplace.Translate(direction.Scale(speed * parsecsPerGyrPerKmPerSec * ss.Age))
+
+		// generate the stars and planets in the system from its own seed
+		ss.Seed = DeriveSeed(a.Catalog.Seed, uint64(len(a.Catalog.StarSystems)))
+		ss.ID, ss.Designation = newSystemID(ss.Seed), associationDesignation(a.ID, a.Catalog.Seed, len(a.Catalog.StarSystems)+1)
+		ss.generate(prng)
+		a.Catalog.StarSystems = append(a.Catalog.StarSystems, ss)
+	}
+	a.remeasure()
+
+	return a, nil
+}
+
+// Density returns the number of members per cubic parsec.
+func (a *Association_t) Density() float64 {
+	return float64(a.Catalog.Length()) / (4.0 / 3.0 * math.Pi * a.Radius * a.Radius * a.Radius)
+}
+
+// remeasure sets the radius to the distance to the farthest member, or the initial
+// radius if that is larger. It is called again after the catalog has been filtered.
+func (a *Association_t) remeasure() {
+	a.Radius = a.InitialRadius
+	for _, ss := range a.Catalog.StarSystems {
+		a.Radius = math.Max(a.Radius, ss.Coordinates.DistanceTo(Coordinates{}))
+	}
+	a.Catalog.Radius = a.Radius
+}
+
+// MoveTo places the association at the center. The coordinates of the members
+// stay relative to the center of the association.
+func (a *Association_t) MoveTo(center Coordinates) {
+	a.Center = center
+	a.Catalog.Coordinates = center
+}
+
+// setID numbers the association and tags every member with the number.
+// The designations of the members include the number of the association.
+func (a *Association_t) setID(id int) {
+	a.ID = id
+	for n, ss := range a.Catalog.StarSystems {
+		ss.Association, ss.Designation = id, associationDesignation(id, a.Catalog.Seed, n+1)
+	}
+	a.Catalog.Reindex()
+}
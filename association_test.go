@@ -0,0 +1,139 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow_test
+
+import (
+	"github.com/mdhender/aow"
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestNewStellarAssociation(t *testing.T) {
+	members := make(map[aow.AssociationKind_e]int)
+	massive := make(map[aow.AssociationKind_e]int)
+	associations := make(map[aow.AssociationKind_e]int)
+	for seed := uint64(1); seed <= 40; seed++ {
+		a, err := aow.NewStellarAssociation(aow.NewSeededPRNG(seed))
+		if err != nil {
+			t.Fatalf("NewStellarAssociation() error = %v", err)
+		}
+		associations[a.Kind]++
+		if a.Age <= 0 || a.Age > 0.030 {
+			t.Errorf("%d: NewStellarAssociation() age %f, want young", seed, a.Age)
+		}
+		if a.Radius < a.InitialRadius || a.Catalog.Radius != a.Radius {
+			t.Errorf("%d: NewStellarAssociation() radius %f catalog %f, want at least the initial radius %f", seed, a.Radius, a.Catalog.Radius, a.InitialRadius)
+		}
+		if a.Catalog.Length() == 0 {
+			t.Errorf("%d: NewStellarAssociation() has no members", seed)
+		}
+		expanded, farthest := false, a.InitialRadius
+		for _, ss := range a.Catalog.StarSystems {
+			members[a.Kind]++
+			if ss.Primary().Mass >= 2 {
+				massive[a.Kind]++
+			}
+			if !ss.InAssociation || ss.InCluster || ss.Population != aow.YoungPopulationI || ss.Age > a.Age {
+				t.Errorf("%d: NewStellarAssociation() member in association %v population %v age %f", seed, ss.InAssociation, ss.Population, ss.Age)
+			}
+			d := ss.Coordinates.DistanceTo(aow.Coordinates{})
+			if d > a.Radius {
+				t.Errorf("%d: NewStellarAssociation() member at %f, want within %f", seed, d, a.Radius)
+			}
+			expanded, farthest = expanded || d > a.InitialRadius, math.Max(farthest, d)
+		}
+		if farthest != a.Radius {
+			t.Errorf("%d: NewStellarAssociation() radius %f, want the farthest member at %f", seed, a.Radius, farthest)
+		}
+		if !expanded && a.Catalog.Length() > 10 {
+			t.Errorf("%d: NewStellarAssociation() no member has left the initial radius %f", seed, a.InitialRadius)
+		}
+	}
+	if associations[aow.TAssociation] == 0 || associations[aow.OBAssociation] == 0 {
+		t.Fatalf("NewStellarAssociation() kinds %v, want both T and OB", associations)
+	}
+	if members[aow.OBAssociation]/associations[aow.OBAssociation] <= members[aow.TAssociation]/associations[aow.TAssociation] {
+		t.Errorf("NewStellarAssociation() OB associations average %d members, want more than T associations %d",
+			members[aow.OBAssociation]/associations[aow.OBAssociation], members[aow.TAssociation]/associations[aow.TAssociation])
+	}
+	if float64(massive[aow.OBAssociation])/float64(members[aow.OBAssociation]) <= float64(massive[aow.TAssociation])/float64(members[aow.TAssociation]) {
+		t.Errorf("NewStellarAssociation() OB associations have fewer massive primaries than T associations")
+	}
+}
+
+func TestGenerator_StellarAssociation(t *testing.T) {
+	g, err := aow.New(100, rand.NewPCG(0xcafe, 0xcafe), aow.SurveyCatalog)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := g.BackgroundPopulation(); err != nil {
+		t.Fatalf("BackgroundPopulation() error = %v", err)
+	}
+	background := g.Catalog.Length()
+
+	origin := aow.Coordinates{X: 10, Y: 5, Z: -2}
+	a, err := g.StellarAssociation(origin)
+	if err != nil {
+		t.Fatalf("StellarAssociation() error = %v", err)
+	}
+	if a.ID != 1 || a.Center != origin || len(g.Associations) != 1 {
+		t.Errorf("StellarAssociation() id %d center %v, want 1 %v", a.ID, a.Center, origin)
+	}
+	g.Catalog.MergeAssociation(a)
+	for _, ss := range g.Catalog.StarSystems[background:] {
+		if ss.Association != 1 || !aow.IsAssociationMember(ss) {
+			t.Errorf("MergeAssociation() member tagged with association %d", ss.Association)
+		}
+		if d := ss.Coordinates.DistanceTo(origin); d > a.Radius {
+			t.Errorf("MergeAssociation() member is %f from the center, want within %f", d, a.Radius)
+		}
+	}
+	if n := g.Catalog.Length() - background; n != a.Catalog.Length() {
+		t.Errorf("MergeAssociation() added %d members, want %d", n, a.Catalog.Length())
+	}
+}
+
+func TestGenerator_StellarAssociationReference(t *testing.T) {
+	// the default interest keeps every member of an association, like the members of a cluster
+	g, err := aow.New(100, rand.NewPCG(0xcafe, 0xcafe), aow.ReferenceCatalog)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	a, err := g.StellarAssociation(aow.Coordinates{})
+	if err != nil {
+		t.Fatalf("StellarAssociation() error = %v", err)
+	}
+	standalone, err := aow.New(100, rand.NewPCG(0xcafe, 0xcafe), aow.SurveyCatalog)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	survey, err := standalone.StellarAssociation(aow.Coordinates{})
+	if err != nil {
+		t.Fatalf("StellarAssociation() error = %v", err)
+	}
+	if a.Catalog.Length() != survey.Catalog.Length() {
+		t.Errorf("StellarAssociation() reference catalog kept %d of %d members", a.Catalog.Length(), survey.Catalog.Length())
+	}
+}
+
+func TestGenerator_StellarAssociationWithInterest(t *testing.T) {
+	// the radius is measured from the members kept in a reference catalog
+	g, err := aow.New(100, rand.NewPCG(0xcafe, 0xcafe), aow.ReferenceCatalog, aow.WithInterest(aow.IsBrightStar))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	for n := 0; n < 10; n++ {
+		a, err := g.StellarAssociation(aow.Coordinates{})
+		if err != nil {
+			t.Fatalf("StellarAssociation() error = %v", err)
+		}
+		farthest := a.InitialRadius
+		for _, ss := range a.Catalog.StarSystems {
+			farthest = math.Max(farthest, ss.Coordinates.DistanceTo(aow.Coordinates{}))
+		}
+		if a.Radius != farthest || a.Catalog.Radius != farthest {
+			t.Errorf("StellarAssociation() radius %f catalog %f, want the farthest member kept at %f", a.Radius, a.Catalog.Radius, farthest)
+		}
+	}
+}
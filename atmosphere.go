@@ -0,0 +1,233 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow
+
+import (
+	"fmt"
+	"math"
+)
+
+// AtmosphereComposition_e is the broad chemical composition of an atmosphere.
+type AtmosphereComposition_e int
+
+const (
+	// NoAtmosphere means the planet can't retain even carbon dioxide.
+	NoAtmosphere AtmosphereComposition_e = iota
+	// CarbonDioxideAtmosphere is a mostly carbon dioxide atmosphere.
+	CarbonDioxideAtmosphere
+	// NitrogenAtmosphere is a mostly nitrogen atmosphere with traces of carbon dioxide and water vapor.
+	NitrogenAtmosphere
+	// ReducingAtmosphere is a nitrogen and methane atmosphere, typical of icy worlds.
+	ReducingAtmosphere
+	// HydrogenAtmosphere is a primordial hydrogen and helium envelope.
+	HydrogenAtmosphere
+)
+
+// String implements the Stringer interface.
+func (a AtmosphereComposition_e) String() string {
+	switch a {
+	case NoAtmosphere:
+		return "none"
+	case CarbonDioxideAtmosphere:
+		return "carbon dioxide"
+	case NitrogenAtmosphere:
+		return "nitrogen"
+	case ReducingAtmosphere:
+		return "reducing"
+	case HydrogenAtmosphere:
+		return "hydrogen"
+	}
+	return fmt.Sprintf("AtmosphereComposition_e(%d)", int(a))
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (a AtmosphereComposition_e) MarshalText() ([]byte, error) {
+	switch a {
+	case NoAtmosphere, CarbonDioxideAtmosphere, NitrogenAtmosphere, ReducingAtmosphere, HydrogenAtmosphere:
+		return []byte(a.String()), nil
+	}
+	return nil, fmt.Errorf("atmosphere %d: %w", int(a), ErrInvalidCatalog)
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (a *AtmosphereComposition_e) UnmarshalText(text []byte) error {
+	for _, value := range []AtmosphereComposition_e{NoAtmosphere, CarbonDioxideAtmosphere, NitrogenAtmosphere, ReducingAtmosphere, HydrogenAtmosphere} {
+		if string(text) == value.String() {
+			*a = value
+			return nil
+		}
+	}
+	return fmt.Errorf("atmosphere %q: %w", string(text), ErrInvalidCatalog)
+}
+
+// WorldClass_e is a summary of the surface conditions of a world.
+type WorldClass_e int
+
+const (
+	// AirlessWorld has no significant atmosphere, like the Moon or Mercury.
+	AirlessWorld WorldClass_e = iota
+	// MartianWorld has a thin carbon dioxide atmosphere and is cold and dry.
+	MartianWorld
+	// VenusianWorld has a dense atmosphere and a runaway greenhouse effect.
+	VenusianWorld
+	// EarthLikeWorld has a nitrogen atmosphere, liquid water and a temperate surface.
+	EarthLikeWorld
+	// OceanWorld is covered by a global ocean.
+	OceanWorld
+	// IceWorld has an atmosphere but its volatiles are frozen, like Titan.
+	IceWorld
+	// GasDwarfWorld has kept a primordial hydrogen envelope.
+	GasDwarfWorld
+	// PlanetoidWorld is the largest body in a planetoid belt.
+	PlanetoidWorld
+)
+
+// String implements the Stringer interface.
+func (c WorldClass_e) String() string {
+	switch c {
+	case AirlessWorld:
+		return "Airless"
+	case MartianWorld:
+		return "Martian"
+	case VenusianWorld:
+		return "Venusian"
+	case EarthLikeWorld:
+		return "Earth-like"
+	case OceanWorld:
+		return "Ocean"
+	case IceWorld:
+		return "Ice"
+	case GasDwarfWorld:
+		return "Gas dwarf"
+	case PlanetoidWorld:
+		return "Planetoid"
+	}
+	return fmt.Sprintf("WorldClass_e(%d)", int(c))
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (c WorldClass_e) MarshalText() ([]byte, error) {
+	switch c {
+	case AirlessWorld, MartianWorld, VenusianWorld, EarthLikeWorld, OceanWorld, IceWorld, GasDwarfWorld, PlanetoidWorld:
+		return []byte(c.String()), nil
+	}
+	return nil, fmt.Errorf("world class %d: %w", int(c), ErrInvalidCatalog)
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (c *WorldClass_e) UnmarshalText(text []byte) error {
+	for _, value := range []WorldClass_e{AirlessWorld, MartianWorld, VenusianWorld, EarthLikeWorld, OceanWorld, IceWorld, GasDwarfWorld, PlanetoidWorld} {
+		if string(text) == value.String() {
+			*c = value
+			return nil
+		}
+	}
+	return fmt.Errorf("world class %q: %w", string(text), ErrInvalidCatalog)
+}
+
+// molecular weights of the gases that control atmosphere retention
+const (
+	molecularWeightHydrogen      = 2.0
+	molecularWeightWater         = 18.0
+	molecularWeightNitrogen      = 28.0
+	molecularWeightCarbonDioxide = 44.0
+)
+
+// PlaceAtmospheres generates the atmosphere and hydrosphere of every terrestrial
+// planet and planetoid orbiting the star. The planets must already have been placed.
+func PlaceAtmospheres(star *Star_t, prng PRNG) {
+	for _, orbit := range star.Orbits {
+		if orbit.Planet != nil {
+			GenerateAtmosphere(*star, orbit, orbit.Planet, prng)
+		}
+	}
+}
+
+// GenerateAtmosphere generates the atmosphere, hydrosphere and surface temperature of the planet.
+//
+// A planet retains a gas if its escape velocity is at least six times the thermal velocity
+// of the gas at the exospheric temperature. The lightest gas retained decides the composition
+// class, and the atmospheric mass, hydrographic coverage and greenhouse effect follow from it.
+func GenerateAtmosphere(star Star_t, orbit Orbit_t, p *Planet_t, prng PRNG) {
+	beyondSnowLine := orbit.Radius >= star.Disc.SnowLine
+
+	p.EscapeVelocity = 11.186 * math.Sqrt(p.Mass/p.Radius)
+	p.BlackbodyTemperature = 278 * math.Pow(star.State.Luminosity, 0.25) / math.Sqrt(orbit.Radius)
+	// the exosphere is heated by ultraviolet light to several times the blackbody temperature
+	exosphericTemperature := 4 * p.BlackbodyTemperature
+	p.MinimumMolecularWeight = 0.898 * exosphericTemperature / (p.EscapeVelocity * p.EscapeVelocity)
+
+	// a planetoid never holds an atmosphere
+	if orbit.Content == PlanetoidBelt {
+		p.Atmosphere, p.AtmosphericMass, p.Pressure, p.Hydrographics = NoAtmosphere, 0, 0, 0
+		p.SurfaceTemperature = p.BlackbodyTemperature * math.Pow(1-0.1, 0.25)
+		p.Class = PlanetoidWorld
+		return
+	}
+
+	// the composition depends on the lightest gas retained, where the planet formed, and how hot it is
+	prng = prng.WithLabel("atmospheric mass")
+	var albedo float64
+	switch {
+	case p.MinimumMolecularWeight > molecularWeightCarbonDioxide:
+		p.Atmosphere, p.AtmosphericMass, albedo = NoAtmosphere, 0, 0.1
+	case p.MinimumMolecularWeight <= molecularWeightHydrogen && p.Mass >= 2:
+		p.Atmosphere, p.AtmosphericMass, albedo = HydrogenAtmosphere, 100*prng.RollD6(3)/10.5*p.Mass, 0.4
+	case p.MinimumMolecularWeight > molecularWeightNitrogen:
+		p.Atmosphere, p.AtmosphericMass, albedo = CarbonDioxideAtmosphere, 0.01*prng.RollD6(3)/10.5*p.Mass, 0.2
+	case beyondSnowLine:
+		p.Atmosphere, p.AtmosphericMass, albedo = ReducingAtmosphere, 1.5*prng.RollD6(3)/10.5*p.Mass, 0.3
+	case p.BlackbodyTemperature > 300:
+		// too hot to keep surface water; the carbon dioxide stays in the atmosphere
+		p.Atmosphere, p.AtmosphericMass, albedo = CarbonDioxideAtmosphere, 90*prng.RollD6(3)/10.5*p.Mass, 0.75
+	default:
+		p.Atmosphere, p.AtmosphericMass, albedo = NitrogenAtmosphere, prng.RollD6(3)/10.5*p.Mass, 0.3
+	}
+	p.Pressure = p.AtmosphericMass * p.Gravity
+
+	// the greenhouse effect grows with surface pressure
+	greenhouse := 0.134 * math.Pow(p.Pressure, 0.62)
+	p.SurfaceTemperature = p.BlackbodyTemperature * math.Pow(1-albedo, 0.25) * (1 + greenhouse)
+
+	// surface water needs an atmosphere that holds water vapor
+	p.Hydrographics = 0
+	if p.MinimumMolecularWeight <= molecularWeightWater && p.Atmosphere != HydrogenAtmosphere && p.SurfaceTemperature < 373 {
+		if beyondSnowLine {
+			// planets that formed beyond the snow line are rich in water
+			p.Hydrographics = 1
+		} else {
+			p.Hydrographics = min(max((prng.WithLabel("hydrographics").RollD6(3)-7)/10, 0), 1)
+		}
+	}
+
+	p.Class = worldClass(p)
+}
+
+// worldClass returns the class of the world based on its atmosphere and surface.
+func worldClass(p *Planet_t) WorldClass_e {
+	switch p.Atmosphere {
+	case NoAtmosphere:
+		return AirlessWorld
+	case HydrogenAtmosphere:
+		return GasDwarfWorld
+	}
+	switch {
+	case p.SurfaceTemperature >= 373 || (p.Atmosphere == CarbonDioxideAtmosphere && p.Pressure > 10):
+		return VenusianWorld
+	case p.Atmosphere == CarbonDioxideAtmosphere:
+		return MartianWorld
+	case p.SurfaceTemperature < 278:
+		return IceWorld
+	case p.Hydrographics >= 0.95:
+		return OceanWorld
+	case p.SurfaceTemperature > 303:
+		// a moist greenhouse that is on its way to becoming Venusian
+		return VenusianWorld
+	case p.Hydrographics < 0.3 || p.Pressure < 0.5:
+		// too dry or too thin to be Earth-like
+		return MartianWorld
+	case p.Atmosphere == NitrogenAtmosphere:
+		return EarthLikeWorld
+	}
+	return IceWorld
+}
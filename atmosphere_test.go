@@ -0,0 +1,60 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow_test
+
+import (
+	"github.com/mdhender/aow"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestGenerateAtmosphere(t *testing.T) {
+	p := aow.NewPRNG(rand.NewPCG(0xcafe, 0xcafe)) // Use a fixed seed for reproducibility
+	sol := aow.Star_t{Mass: 1.0, State: aow.StellarEvolution(1.0, 4.6), Disc: aow.NewDisc([]aow.Star_t{{Mass: 1.0}}, 0)}
+	for _, tc := range []struct {
+		name       string
+		radius     float64
+		planet     aow.Planet_t
+		atmosphere aow.AtmosphereComposition_e
+		worldClass []aow.WorldClass_e
+	}{
+		{"moon", 1.0, aow.Planet_t{Mass: 0.0123, Density: 0.61, Radius: 0.273, Gravity: 0.166}, aow.NoAtmosphere, []aow.WorldClass_e{aow.AirlessWorld}},
+		{"mars", 1.52, aow.Planet_t{Mass: 0.107, Density: 0.71, Radius: 0.532, Gravity: 0.38}, aow.CarbonDioxideAtmosphere, []aow.WorldClass_e{aow.MartianWorld}},
+		{"venus", 0.723, aow.Planet_t{Mass: 0.815, Density: 0.95, Radius: 0.95, Gravity: 0.9}, aow.CarbonDioxideAtmosphere, []aow.WorldClass_e{aow.VenusianWorld}},
+		{"earth", 1.0, aow.Planet_t{Mass: 1.0, Density: 1.0, Radius: 1.0, Gravity: 1.0}, aow.NitrogenAtmosphere, []aow.WorldClass_e{aow.EarthLikeWorld, aow.MartianWorld, aow.OceanWorld, aow.IceWorld}},
+	} {
+		for n := 0; n < 100; n++ {
+			planet := tc.planet
+			aow.GenerateAtmosphere(sol, aow.Orbit_t{Radius: tc.radius, Content: aow.TerrestrialPlanet}, &planet, p)
+			if planet.Atmosphere != tc.atmosphere {
+				t.Errorf("%s: GenerateAtmosphere() atmosphere = %v, want %v", tc.name, planet.Atmosphere, tc.atmosphere)
+			}
+			var ok bool
+			for _, class := range tc.worldClass {
+				ok = ok || planet.Class == class
+			}
+			if !ok {
+				t.Errorf("%s: GenerateAtmosphere() class = %v, want one of %v", tc.name, planet.Class, tc.worldClass)
+			}
+		}
+	}
+}
+
+func TestGenerateAtmosphere_EarthLike(t *testing.T) {
+	p := aow.NewPRNG(rand.NewPCG(0xcafe, 0xcafe)) // Use a fixed seed for reproducibility
+	sol := aow.Star_t{Mass: 1.0, State: aow.StellarEvolution(1.0, 4.6), Disc: aow.NewDisc([]aow.Star_t{{Mass: 1.0}}, 0)}
+	var earthLike int
+	for n := 0; n < 100; n++ {
+		planet := aow.Planet_t{Mass: 1.0, Density: 1.0, Radius: 1.0, Gravity: 1.0}
+		aow.GenerateAtmosphere(sol, aow.Orbit_t{Radius: 1.0, Content: aow.TerrestrialPlanet}, &planet, p)
+		if planet.IsEarthLike() {
+			earthLike++
+			if planet.SurfaceTemperature < 273 || planet.SurfaceTemperature > 373 || planet.Hydrographics <= 0 {
+				t.Errorf("GenerateAtmosphere() Earth-like planet %+v has no liquid water", planet)
+			}
+		}
+	}
+	if earthLike == 0 {
+		t.Errorf("GenerateAtmosphere() never produced an Earth-like world at 1 AU from Sol")
+	}
+}
@@ -3,16 +3,21 @@
 package aow
 
 import (
-	"log"
+	"fmt"
 	"math"
 	"sort"
 )
 
 type Catalog_t struct {
-	Kind        Catalog_e
-	Radius      float64     // in parsecs
-	Coordinates Coordinates // relative to an arbitrary point
-	StarSystems []*StarSystem_t
+	Kind            Catalog_e
+	Seed            uint64            // the seed the star systems were derived from
+	PopulationModel PopulationModel_t // the model used to generate the background population
+	Radius          float64           // in parsecs
+	Coordinates     Coordinates       // relative to an arbitrary point
+	StarSystems     []*StarSystem_t
+
+	index  *kdTree_t      // spatial index; nil until the first query or after the systems change
+	lookup *lookupIndex_t // ID and designation index; nil until the first lookup or after the systems change
 }
 
 type Catalog_e int
@@ -25,11 +30,66 @@ const (
 	ReferenceCatalog
 )
 
+// String implements the Stringer interface.
+func (k Catalog_e) String() string {
+	switch k {
+	case SurveyCatalog:
+		return "survey"
+	case ReferenceCatalog:
+		return "reference"
+	}
+	return fmt.Sprintf("Catalog_e(%d)", int(k))
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (k Catalog_e) MarshalText() ([]byte, error) {
+	switch k {
+	case SurveyCatalog, ReferenceCatalog:
+		return []byte(k.String()), nil
+	}
+	return nil, fmt.Errorf("catalog kind %d: %w", int(k), ErrInvalidCatalog)
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (k *Catalog_e) UnmarshalText(text []byte) error {
+	for _, value := range []Catalog_e{SurveyCatalog, ReferenceCatalog} {
+		if string(text) == value.String() {
+			*k = value
+			return nil
+		}
+	}
+	return fmt.Errorf("catalog kind %q: %w", string(text), ErrInvalidCatalog)
+}
+
 // NewBackgroundPopulation creates a catalog containing the background population of a neighborhood.
 //
 // Uses the population model to generate the initial set of star systems.
+// The first draw from the PRNG is the seed of the catalog.
 func NewBackgroundPopulation(pm PopulationModel_t, prng PRNG) (*Catalog_t, error) {
-	var c Catalog_t
+	c := &Catalog_t{Seed: prng.WithLabel("catalog seed").Uint64()}
+	if err := c.AddBackgroundShell(pm, 0, prng); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// AddBackgroundShell adds the background population of the shell between the inner
+// radius (in parsecs) and the radius of the population model to the catalog.
+//
+// It is used to grow an existing catalog without changing the systems already in it.
+// New systems are seeded from the catalog seed and their index in the catalog.
+func (c *Catalog_t) AddBackgroundShell(pm PopulationModel_t, innerRadius float64, prng PRNG) error {
+	c.PopulationModel, c.Radius = pm, pm.Radius
+	defer c.Reindex()
+
+	// there is no shell to fill
+	if pm.Radius <= innerRadius {
+		return nil
+	}
+
+	// the fraction of the radius and volume of the population model taken up by the inner sphere
+	innerPct := innerRadius / pm.Radius
+	volume := pm.Volume * (1 - innerPct*innerPct*innerPct)
 
 	for _, v := range []struct {
 		key   StellarPopulation_e
@@ -41,200 +101,35 @@ func NewBackgroundPopulation(pm PopulationModel_t, prng PRNG) (*Catalog_t, error
 		{key: DiskPopulationII, value: pm.DiskPopulationII},
 		{key: HaloPopulationII, value: pm.HaloPopulationII},
 	} {
-		numberOfStarSystems := int(math.Ceil(prng.Vary10Pct(v.value.Density * pm.Volume)))
+		numberOfStarSystems := int(math.Ceil(prng.WithLabel("population density").Vary10Pct(v.value.Density * volume)))
 		for i := 0; i < numberOfStarSystems; i++ {
-			c.StarSystems = append(c.StarSystems, &StarSystem_t{
+			ss := &StarSystem_t{
 				Population: v.key,
 				// generate a random age for the star system
-				Age: v.value.BaseAge + v.value.AgeRange*prng.RollPercentile(),
+				Age: v.value.BaseAge + v.value.AgeRange*prng.WithLabel("system age").RollPercentile(),
 				// generate a random position for the star system
-				Coordinates: prng.GenXYZ().Scale(pm.Radius),
-			})
+				Coordinates: prng.WithLabel("system coordinates").GenZonedXYZ(innerPct, 1).Scale(pm.Radius),
+			}
+			// generate the stars and planets in the system from its own seed
+			ss.Seed = DeriveSeed(c.Seed, uint64(len(c.StarSystems)))
+			ss.ID, ss.Designation = newSystemID(ss.Seed), backgroundDesignation(len(c.StarSystems)+1)
+			ss.generate(prng)
+			c.StarSystems = append(c.StarSystems, ss)
 		}
 	}
 
-	return &c, nil
+	return nil
 }
 
-const (
-	minPctClusterCoreZone  float64 = 0.0
-	maxPctClusterCoreZone  float64 = 0.05
-	minPctTidalRadiusZone  float64 = 0.05
-	maxPctTidalRadiusZone  float64 = 0.2
-	minPctExtendedHaloZone float64 = 0.2
-	maxPctExtendedHaloZone float64 = 1.0
-)
-
-func NewOpenCluster(prng PRNG) (*Catalog_t, error) {
-	// cluster can be tightly or loosely bound.
-	isTightlyBound := prng.RollD6(3) <= 5
-
-	// determine the age of the cluster (in billions of years)
-	var clusterAge float64
-	if isTightlyBound {
-		switch n := prng.RollD100(); {
-		case n <= 2:
-			clusterAge = 0.0 + 0.1*prng.RollPercentile()
-		case n <= 4:
-			clusterAge = 0.1 + 0.1*prng.RollPercentile()
-		case n <= 6:
-			clusterAge = 0.2 + 0.1*prng.RollPercentile()
-		case n <= 8:
-			clusterAge = 0.3 + 0.1*prng.RollPercentile()
-		case n <= 10:
-			clusterAge = 0.4 + 0.1*prng.RollPercentile()
-		case n <= 12:
-			clusterAge = 0.5 + 0.1*prng.RollPercentile()
-		case n <= 14:
-			clusterAge = 0.6 + 0.1*prng.RollPercentile()
-		case n <= 16:
-			clusterAge = 0.7 + 0.1*prng.RollPercentile()
-		case n <= 18:
-			clusterAge = 0.8 + 0.1*prng.RollPercentile()
-		case n <= 20:
-			clusterAge = 0.9 + 0.1*prng.RollPercentile()
-		case n <= 45:
-			clusterAge = 1.0 + 2.0*prng.RollPercentile()
-		default:
-			clusterAge = 3.0 + 5.0*prng.RollPercentile()
-		}
-	} else {
-		switch n := prng.RollD100(); {
-		case n <= 21:
-			clusterAge = 0.0 + 0.1*prng.RollPercentile()
-		case n <= 38:
-			clusterAge = 0.1 + 0.1*prng.RollPercentile()
-		case n <= 52:
-			clusterAge = 0.2 + 0.1*prng.RollPercentile()
-		case n <= 64:
-			clusterAge = 0.3 + 0.1*prng.RollPercentile()
-		case n <= 73:
-			clusterAge = 0.4 + 0.1*prng.RollPercentile()
-		case n <= 81:
-			clusterAge = 0.5 + 0.1*prng.RollPercentile()
-		case n <= 87:
-			clusterAge = 0.6 + 0.1*prng.RollPercentile()
-		case n <= 92:
-			clusterAge = 0.7 + 0.1*prng.RollPercentile()
-		case n <= 96:
-			clusterAge = 0.8 + 0.1*prng.RollPercentile()
-		default:
-			clusterAge = 0.9 + 0.1*prng.RollPercentile()
-		}
-	}
-
-	// population group depends on the age of the cluster
-	var stpop StellarPopulation_e
-	if clusterAge < 2.0 {
-		stpop = YoungPopulationI
-	} else if clusterAge < 5.0 {
-		stpop = IntermediatePopulationI
-	} else {
-		stpop = OldPopulationI
-	}
-
-	// generate the initial radius (in parsecs), give or take 0.25 parsecs
-	clusterRadius := prng.RollD6(2) / 2
-	log.Printf("cluster radius: %f\n", clusterRadius)
-	clusterRadius += (prng.VaryNPct(1.0, 0.25) - 1)
-	log.Printf("cluster radius: %f\n", clusterRadius)
-
-	// initial number of star systems in the cluster
-	numberOfStarSystems := prng.RollD6(2) / 2
-	if isTightlyBound && numberOfStarSystems < 3.5 {
-		numberOfStarSystems = 3.5
-	}
-	numberOfStarSystems = math.Floor(numberOfStarSystems * math.Pow(clusterRadius, 3))
-	log.Printf("number of star systems: %f\n", numberOfStarSystems)
-
-	// determine the effective age of the cluster for the evaporation table
-	effectiveClusterAge := clusterAge
-	if isTightlyBound {
-		effectiveClusterAge = clusterAge / 10
-	}
-	log.Printf("effective cluster age: %f\n", effectiveClusterAge)
-
-	// use the cluster evaporation table to get radius (as a percentage) for each zone
-	var corePct, tidalPct, extendedHaloPct float64
-	switch {
-	case effectiveClusterAge < 0.1:
-		corePct, tidalPct, extendedHaloPct = 1.00, 0.00, 0.00
-	case effectiveClusterAge < 0.2:
-		corePct, tidalPct, extendedHaloPct = 0.80, 0.20, 0.00
-	case effectiveClusterAge < 0.3:
-		corePct, tidalPct, extendedHaloPct = 0.64, 0.32, 0.04
-	case effectiveClusterAge < 0.4:
-		corePct, tidalPct, extendedHaloPct = 0.51, 0.38, 0.10
-	case effectiveClusterAge < 0.5:
-		corePct, tidalPct, extendedHaloPct = 0.41, 0.41, 0.15
-	case effectiveClusterAge < 0.6:
-		corePct, tidalPct, extendedHaloPct = 0.33, 0.41, 0.20
-	case effectiveClusterAge < 0.7:
-		corePct, tidalPct, extendedHaloPct = 0.26, 0.39, 0.25
-	case effectiveClusterAge < 0.8:
-		corePct, tidalPct, extendedHaloPct = 0.21, 0.37, 0.28
-	case effectiveClusterAge < 0.9:
-		corePct, tidalPct, extendedHaloPct = 0.17, 0.33, 0.29
-	case effectiveClusterAge < 1.0:
-		corePct, tidalPct, extendedHaloPct = 0.13, 0.30, 0.30
-	default:
-		corePct, tidalPct, extendedHaloPct = 0.11, 0.27, 0.30
-	}
-	log.Printf("core: %f, tidal: %f, extended halo: %f\n", corePct, tidalPct, extendedHaloPct)
-	coreCount, tidalCount, extendedHaloCount := int(corePct*numberOfStarSystems), int(tidalPct*numberOfStarSystems), int(extendedHaloPct*numberOfStarSystems)
-	log.Printf("core count: %d, title count: %d, extended halo count: %d\n", coreCount, tidalCount, extendedHaloCount)
-	if float64(coreCount+tidalCount+extendedHaloCount) < numberOfStarSystems {
-		coreCount++
-		if float64(coreCount+tidalCount+extendedHaloCount) < numberOfStarSystems {
-			tidalCount++
+// CountEarthLikeSystems returns the number of systems with Earth-like planets in the catalog.
+func (c *Catalog_t) CountEarthLikeSystems() int {
+	var n int
+	for _, ss := range c.StarSystems {
+		if ss.HasEarthLikePlanet() {
+			n++
 		}
 	}
-	log.Printf("core count: %d, title count: %d, extended halo count: %d\n", coreCount, tidalCount, extendedHaloCount)
-
-	// we have the information needed to create the catalog for the cluster
-	var catalog Catalog_t
-
-	// create star systems in the cluster core zone
-	log.Printf("gen core %f %f %d/%d\n", minPctClusterCoreZone, maxPctClusterCoreZone, int(corePct*numberOfStarSystems), int(numberOfStarSystems))
-	for ; coreCount > 0; coreCount-- {
-		catalog.StarSystems = append(catalog.StarSystems, &StarSystem_t{
-			Population: stpop,
-			// generate a random age for the star system
-			Age: prng.Vary5Pct(clusterAge),
-			// generate a random position for the star system
-			Coordinates: prng.GenZonedXYZ(minPctClusterCoreZone, maxPctClusterCoreZone).Scale(clusterRadius),
-		})
-	}
-
-	// create star systems in the tidal radius zone
-	log.Printf("gen tidal %f %f %d/%d\n", minPctTidalRadiusZone, maxPctTidalRadiusZone, int(tidalPct*numberOfStarSystems), int(numberOfStarSystems))
-	for ; tidalCount > 0; tidalCount-- {
-		catalog.StarSystems = append(catalog.StarSystems, &StarSystem_t{
-			Population: stpop,
-			// generate a random age for the star system
-			Age: prng.Vary5Pct(clusterAge),
-			// generate a random position for the star system
-			Coordinates: prng.GenZonedXYZ(minPctTidalRadiusZone, maxPctTidalRadiusZone).Scale(clusterRadius),
-		})
-	}
-
-	// create star systems in the extended halo zone
-	log.Printf("gen halo %f %f %d/%d\n", minPctExtendedHaloZone, maxPctExtendedHaloZone, int(extendedHaloPct*numberOfStarSystems), int(numberOfStarSystems))
-	for ; extendedHaloCount > 0; extendedHaloCount-- {
-		catalog.StarSystems = append(catalog.StarSystems, &StarSystem_t{
-			Population: stpop,
-			// generate a random age for the star system
-			Age: prng.Vary5Pct(clusterAge),
-			// generate a random position for the star system
-			Coordinates: prng.GenZonedXYZ(minPctExtendedHaloZone, maxPctExtendedHaloZone).Scale(clusterRadius),
-		})
-	}
-
-	return &catalog, nil
-}
-
-func NewStellarAssociation(prng PRNG) (*Catalog_t, error) {
-	return NewOpenCluster(prng)
+	return n
 }
 
 func (c *Catalog_t) Length() int {
@@ -259,12 +154,49 @@ func (c *Catalog_t) SortByDistance(origin Coordinates) {
 	})
 }
 
+// Filter removes every star system that doesn't satisfy the predicate.
+func (c *Catalog_t) Filter(keep InterestPredicate) {
+	var kept []*StarSystem_t
+	for _, ss := range c.StarSystems {
+		if keep(ss) {
+			kept = append(kept, ss)
+		}
+	}
+	c.StarSystems = kept
+	c.Reindex()
+}
+
+// MergeCluster adds copies of the members of the cluster to the catalog,
+// translated from the center of the cluster.
+func (c *Catalog_t) MergeCluster(cluster *Cluster_t) {
+	c.Merge(cluster.Catalog, cluster.Center)
+}
+
+// MergeAssociation adds copies of the members of the stellar association to the catalog,
+// translated from the center of the association.
+func (c *Catalog_t) MergeAssociation(association *Association_t) {
+	c.Merge(association.Catalog, association.Center)
+}
+
 func (c *Catalog_t) Merge(other *Catalog_t, offset Coordinates) {
+	c.mergeWithin(other, offset, math.Inf(1))
+}
+
+// mergeWithin merges the systems that are within the radius (in parsecs) of the origin after translation.
+// A copy of a system that is already in the catalog (for example, when the same cluster
+// is merged twice) is given a new ID and designation.
+func (c *Catalog_t) mergeWithin(other *Catalog_t, offset Coordinates, radius float64) {
+	lookup := c.lookupIndex()
 	for _, ss := range other.StarSystems {
-		c.StarSystems = append(c.StarSystems, &StarSystem_t{
-			Population:  ss.Population,
-			Age:         ss.Age,
-			Coordinates: ss.Coordinates.Translate(offset),
-		})
+		coordinates := ss.Coordinates.Translate(offset)
+		if coordinates.DistanceTo(Coordinates{}) > radius {
+			continue
+		}
+		nss := ss.clone()
+		nss.Coordinates = coordinates
+		lookup.unique(nss)
+		lookup.add(nss)
+		c.StarSystems = append(c.StarSystems, nss)
 	}
+	c.index = nil
 }
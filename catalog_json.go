@@ -0,0 +1,79 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// catalogSchemaVersion is the version of the JSON document written by Save.
+// Bump it whenever a change to the document would break an older reader.
+const catalogSchemaVersion = 1
+
+// catalogDocument_t is the JSON document for a catalog.
+// Every field in the document is named in camelCase and enumerated values are
+// written as their names, so the document doesn't depend on the order of constants.
+type catalogDocument_t struct {
+	Schema          int               `json:"schema"`
+	Generator       string            `json:"generator"` // the version of the generator that wrote the document
+	Kind            Catalog_e         `json:"kind"`
+	Seed            uint64            `json:"seed"`
+	PopulationModel PopulationModel_t `json:"populationModel"`
+	Radius          float64           `json:"radius"`
+	Coordinates     Coordinates       `json:"coordinates"`
+	StarSystems     []*StarSystem_t   `json:"starSystems"`
+}
+
+// Save writes the catalog to the writer as an indented JSON document.
+// LoadCatalog reads the document back into an identical catalog.
+func (c *Catalog_t) Save(w io.Writer) error {
+	doc := catalogDocument_t{
+		Schema:          catalogSchemaVersion,
+		Generator:       version.String(),
+		Kind:            c.Kind,
+		Seed:            c.Seed,
+		PopulationModel: c.PopulationModel,
+		Radius:          c.Radius,
+		Coordinates:     c.Coordinates,
+		StarSystems:     c.StarSystems,
+	}
+	if doc.StarSystems == nil {
+		doc.StarSystems = []*StarSystem_t{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+// LoadCatalog reads a catalog written by Save.
+//
+// Documents written by any version of the generator can be loaded as long as they use
+// a schema this version understands. Otherwise, it returns ErrUnsupportedSchema.
+// It returns ErrInvalidCatalog if a star system has no stars or an enumerated value
+// (like a stellar population or world class) isn't one this version knows.
+func LoadCatalog(r io.Reader) (*Catalog_t, error) {
+	var doc catalogDocument_t
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+	if doc.Schema != catalogSchemaVersion {
+		return nil, fmt.Errorf("schema %d (generator %q): %w", doc.Schema, doc.Generator, ErrUnsupportedSchema)
+	}
+	for n, ss := range doc.StarSystems {
+		if ss == nil {
+			return nil, fmt.Errorf("star system %d: missing: %w", n+1, ErrInvalidCatalog)
+		} else if len(ss.Stars) == 0 {
+			return nil, fmt.Errorf("star system %d: %q: no stars: %w", n+1, ss.Designation, ErrInvalidCatalog)
+		}
+	}
+	return &Catalog_t{
+		Kind:            doc.Kind,
+		Seed:            doc.Seed,
+		PopulationModel: doc.PopulationModel,
+		Radius:          doc.Radius,
+		Coordinates:     doc.Coordinates,
+		StarSystems:     doc.StarSystems,
+	}, nil
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"github.com/mdhender/aow"
+	"math/rand/v2"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+func TestCatalog_SaveAndLoad(t *testing.T) {
+	g, err := aow.New(20, rand.NewPCG(0xcafe, 0xcafe), aow.ReferenceCatalog, aow.WithEarthLikeSystems(), aow.WithOpenClusters(1), aow.WithStellarAssociations(1), aow.WithNameStyle("norse"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := g.BackgroundPopulation(); err != nil {
+		t.Fatalf("BackgroundPopulation() error = %v", err)
+	}
+
+	var saved bytes.Buffer
+	if err := g.Catalog.Save(&saved); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	var doc struct {
+		Schema    int    `json:"schema"`
+		Generator string `json:"generator"`
+		Seed      uint64 `json:"seed"`
+	}
+	if err := json.Unmarshal(saved.Bytes(), &doc); err != nil {
+		t.Fatalf("Save() wrote invalid JSON: %v", err)
+	}
+	if doc.Schema != 1 || doc.Generator == "" || doc.Seed != g.Catalog.Seed {
+		t.Errorf("Save() schema %d, generator %q, seed %d", doc.Schema, doc.Generator, doc.Seed)
+	}
+
+	loaded, err := aow.LoadCatalog(bytes.NewReader(saved.Bytes()))
+	if err != nil {
+		t.Fatalf("LoadCatalog() error = %v", err)
+	}
+	if loaded.Kind != g.Catalog.Kind || loaded.Seed != g.Catalog.Seed || loaded.Radius != g.Catalog.Radius {
+		t.Errorf("LoadCatalog() kind %d, seed %d, radius %f: want %d, %d, %f", loaded.Kind, loaded.Seed, loaded.Radius, g.Catalog.Kind, g.Catalog.Seed, g.Catalog.Radius)
+	}
+	if loaded.PopulationModel != g.PopulationModel() {
+		t.Errorf("LoadCatalog() population model = %+v, want %+v", loaded.PopulationModel, g.PopulationModel())
+	}
+	if !reflect.DeepEqual(loaded.StarSystems, g.Catalog.StarSystems) {
+		t.Errorf("LoadCatalog() star systems differ from the saved catalog")
+	}
+	var resaved bytes.Buffer
+	if err := loaded.Save(&resaved); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if !bytes.Equal(saved.Bytes(), resaved.Bytes()) {
+		t.Errorf("Save() after LoadCatalog() wrote a different document")
+	}
+	for _, ss := range g.Catalog.StarSystems {
+		if found := loaded.Lookup(ss.ID); found == nil || found.Name != ss.Name {
+			t.Fatalf("Lookup(%v) after LoadCatalog() = %v", ss.ID, found)
+		}
+	}
+}
+
+func TestLoadCatalog_Errors(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		input    string
+		expected error
+	}{
+		{"future schema", `{"schema": 2, "generator": "9.0.0", "starSystems": []}`, aow.ErrUnsupportedSchema},
+		{"missing schema", `{"starSystems": []}`, aow.ErrUnsupportedSchema},
+		{"no stars", `{"schema": 1, "starSystems": [{"designation": "AOW 0001", "stars": []}]}`, aow.ErrInvalidCatalog},
+		{"missing stars", `{"schema": 1, "starSystems": [{"designation": "AOW 0001"}]}`, aow.ErrInvalidCatalog},
+		{"missing system", `{"schema": 1, "starSystems": [null]}`, aow.ErrInvalidCatalog},
+		{"unknown kind", `{"schema": 1, "kind": "atlas", "starSystems": []}`, aow.ErrInvalidCatalog},
+		{"unknown population", `{"schema": 1, "starSystems": [{"population": "population III", "stars": [{}]}]}`, aow.ErrInvalidCatalog},
+		{"unknown phase", `{"schema": 1, "starSystems": [{"stars": [{"state": {"phase": "quark star"}}]}]}`, aow.ErrInvalidCatalog},
+		{"unknown class", `{"schema": 1, "starSystems": [{"stars": [{"orbits": [{"planet": {"class": "Hycean"}}]}]}]}`, aow.ErrInvalidCatalog},
+	} {
+		if _, err := aow.LoadCatalog(strings.NewReader(tc.input)); !errors.Is(err, tc.expected) {
+			t.Errorf("%s: LoadCatalog() error = %v, want %v", tc.name, err, tc.expected)
+		}
+	}
+	for _, input := range []string{``, `{"schema": 1,`, `{"schema": 1, "radius": "far"}`, `{"schema": 1, "kind": 1, "starSystems": []}`} {
+		if _, err := aow.LoadCatalog(strings.NewReader(input)); err == nil {
+			t.Errorf("LoadCatalog(%q) error = nil, want error", input)
+		}
+	}
+}
+
+// TestCatalog_SaveGolden pins the schema of the document written by Save.
+// Run the tests with -update to rewrite the golden file after a deliberate change,
+// and bump the schema version if older readers can't load the new document.
+func TestCatalog_SaveGolden(t *testing.T) {
+	const golden = "testdata/catalog.json"
+	planet := &aow.Planet_t{
+		Mass:                   0.9,
+		Density:                1.0,
+		Radius:                 0.97,
+		Gravity:                0.95,
+		EscapeVelocity:         10.9,
+		BlackbodyTemperature:   255,
+		MinimumMolecularWeight: 4.5,
+		Atmosphere:             aow.NitrogenAtmosphere,
+		AtmosphericMass:        1.1,
+		Pressure:               1.2,
+		Hydrographics:          0.6,
+		SurfaceTemperature:     290,
+		Class:                  aow.EarthLikeWorld,
+	}
+	c := &aow.Catalog_t{
+		Kind: aow.ReferenceCatalog,
+		Seed: 0xcafe,
+		PopulationModel: aow.PopulationModel_t{
+			Radius:           2,
+			Volume:           33.5,
+			YoungPopulationI: aow.BasicPopulationModelTable().YoungPopulationI,
+			HaloPopulationII: aow.BasicPopulationModelTable().HaloPopulationII,
+			CombinedDensity:  0.25,
+		},
+		Radius:      2,
+		Coordinates: aow.Coordinates{X: 1, Y: -2, Z: 3},
+		StarSystems: []*aow.StarSystem_t{{
+			ID:            0x0123456789abcdef,
+			Designation:   "AOW C1-0001",
+			Name:          "Vala",
+			Seed:          0xfeed,
+			Population:    aow.OldPopulationI,
+			Age:           5.5,
+			Coordinates:   aow.Coordinates{X: 0.5, Y: 0.25, Z: -1},
+			InCluster:     true,
+			Cluster:       1,
+			ClusterZone:   aow.TidalRadiusZone,
+			InAssociation: true,
+			MassModifier:  -2,
+			Stars: []aow.Star_t{{
+				Mass:        1,
+				State:       aow.StellarState_t{Phase: aow.MainSequencePhase, Mass: 1, Luminosity: 1, Temperature: 5772, Radius: 1, SpectralClass: "G2 V"},
+				Disc:        aow.Disc_t{InnerLimit: 0.1, OuterLimit: 40, SnowLine: 4.85, ForbiddenZones: []aow.Zone_t{{Inner: 30, Outer: 200}}},
+				Orbits:      []aow.Orbit_t{{Radius: 1, Eccentricity: 0.02, Content: aow.TerrestrialPlanet, Planet: planet}, {Radius: 5.2, Eccentricity: 0.05, Content: aow.GasGiant}},
+				Arrangement: aow.ConventionalGasGiants,
+			}, {
+				Mass:        0.05,
+				BrownDwarf:  true,
+				Orbit:       aow.StellarOrbit_t{Separation: aow.WideSeparation, Radius: 90, Eccentricity: 0.4, ForbiddenZone: aow.Zone_t{Inner: 30, Outer: 200}},
+				State:       aow.StellarState_t{Phase: aow.BrownDwarfPhase, Mass: 0.05, Luminosity: 0.0001, Temperature: 1500, Radius: 0.1, SpectralClass: "L5"},
+				Disc:        aow.Disc_t{InnerLimit: 0.005, OuterLimit: 2, SnowLine: 0.05},
+				Arrangement: aow.NoGasGiants,
+			}},
+		}},
+	}
+
+	var saved bytes.Buffer
+	if err := c.Save(&saved); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	// the generator version changes with every release, so it isn't part of the schema
+	got := regexp.MustCompile(`"generator": "[^"]*"`).ReplaceAll(saved.Bytes(), []byte(`"generator": ""`))
+	if *update {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatalf("WriteFile(%q) error = %v", golden, err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("ReadFile(%q) error = %v", golden, err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("Save() = %s\nwant %s", got, want)
+	}
+
+	loaded, err := aow.LoadCatalog(bytes.NewReader(want))
+	if err != nil {
+		t.Fatalf("LoadCatalog(%q) error = %v", golden, err)
+	}
+	if !reflect.DeepEqual(loaded.StarSystems, c.StarSystems) || loaded.PopulationModel != c.PopulationModel || loaded.Kind != c.Kind {
+		t.Errorf("LoadCatalog(%q) differs from the saved catalog", golden)
+	}
+}
@@ -0,0 +1,372 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// the columns of a catalog sheet, in the order they are written
+const (
+	columnID                 = "id"
+	columnDesignation        = "designation"
+	columnName               = "name"
+	columnPopulation         = "population"
+	columnAge                = "age"
+	columnX                  = "x"
+	columnY                  = "y"
+	columnZ                  = "z"
+	columnDistance           = "distance"
+	columnInCluster          = "in_cluster"
+	columnCluster            = "cluster"
+	columnClusterZone        = "zone"
+	columnInAssociation      = "in_association"
+	columnAssociation        = "association"
+	columnMassModifier       = "mass_modifier"
+	columnSeed               = "seed"
+	columnStars              = "stars"
+	columnPrimaryMass        = "primary_mass"
+	columnSpectralClass      = "spectral_class"
+	columnGasGiants          = "gas_giants"
+	columnTerrestrialPlanets = "terrestrial_planets"
+	columnEarthLike          = "earth_like"
+)
+
+var tableColumns = []string{
+	columnID, columnDesignation, columnName, columnPopulation, columnAge,
+	columnX, columnY, columnZ, columnDistance,
+	columnInCluster, columnCluster, columnClusterZone, columnInAssociation, columnAssociation, columnMassModifier, columnSeed,
+	columnStars, columnPrimaryMass, columnSpectralClass, columnGasGiants, columnTerrestrialPlanets, columnEarthLike,
+}
+
+// the columns a sheet must have to be imported
+var requiredTableColumns = []string{columnPopulation, columnAge, columnX, columnY, columnZ}
+
+// WriteCSV writes the catalog as comma-separated values, one row per star system.
+// See WriteTable for the columns.
+func (c *Catalog_t) WriteCSV(w io.Writer) error {
+	return c.WriteTable(w, ',')
+}
+
+// WriteTSV writes the catalog as tab-separated values, one row per star system.
+// See WriteTable for the columns.
+func (c *Catalog_t) WriteTSV(w io.Writer) error {
+	return c.WriteTable(w, '\t')
+}
+
+// WriteTable writes the catalog as a sheet with a header row followed by one row per
+// star system, with the fields separated by the delimiter.
+//
+// The distance and the star and planet columns summarize the system for readers of
+// the sheet. They are ignored by ReadTable, which regenerates the details from the seed.
+func (c *Catalog_t) WriteTable(w io.Writer, delimiter rune) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = delimiter
+	if err := cw.Write(tableColumns); err != nil {
+		return err
+	}
+	for _, ss := range c.StarSystems {
+		if err := cw.Write(tableRow(ss)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// tableRow returns the fields of the row for the star system, in the order of tableColumns.
+func tableRow(ss *StarSystem_t) []string {
+	var spectralClass string
+	var primaryMass float64
+	var gasGiants, terrestrialPlanets int
+	if primary := ss.Primary(); primary != nil {
+		primaryMass, spectralClass = primary.Mass, primary.State.SpectralClass
+	}
+	for _, star := range ss.Stars {
+		for _, orbit := range star.Orbits {
+			switch orbit.Content {
+			case GasGiant:
+				gasGiants++
+			case TerrestrialPlanet:
+				terrestrialPlanets++
+			}
+		}
+	}
+	return []string{
+		ss.ID.String(),
+		ss.Designation,
+		ss.Name,
+		ss.Population.String(),
+		formatTableFloat(ss.Age),
+		formatTableFloat(ss.Coordinates.X),
+		formatTableFloat(ss.Coordinates.Y),
+		formatTableFloat(ss.Coordinates.Z),
+		strconv.FormatFloat(ss.Coordinates.DistanceTo(Coordinates{}), 'f', 3, 64),
+		strconv.FormatBool(ss.InCluster),
+		strconv.Itoa(ss.Cluster),
+		ss.ClusterZone.String(),
+		strconv.FormatBool(ss.InAssociation),
+		strconv.Itoa(ss.Association),
+		strconv.Itoa(ss.MassModifier),
+		fmt.Sprintf("%016x", ss.Seed),
+		strconv.Itoa(len(ss.Stars)),
+		strconv.FormatFloat(primaryMass, 'f', 3, 64),
+		spectralClass,
+		strconv.Itoa(gasGiants),
+		strconv.Itoa(terrestrialPlanets),
+		strconv.FormatBool(ss.HasEarthLikePlanet()),
+	}
+}
+
+// formatTableFloat formats the value with as many digits as needed to read it back exactly.
+// It never uses an exponent, since spreadsheets don't always read them back as numbers.
+func formatTableFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// ReadCSV reads a catalog from comma-separated values. See ReadTable.
+func ReadCSV(r io.Reader) (*Catalog_t, error) {
+	return ReadTable(r, ',')
+}
+
+// ReadTSV reads a catalog from tab-separated values. See ReadTable.
+func ReadTSV(r io.Reader) (*Catalog_t, error) {
+	return ReadTable(r, '\t')
+}
+
+// ReadTable reads a catalog from a sheet written by WriteTable, which may have been
+// edited by hand.
+//
+// Columns are found by the name in the header row, so they may be reordered or removed.
+// Only the population, age and coordinates are required. The details of each system are
+// regenerated from the seed, population, age and mass modifier, so the summary columns
+// are ignored. A blank in_cluster column is true if the row has a cluster number or
+// zone, and a blank in_association column is true if the row has an association number.
+// Rows with a blank seed are seeded from their line number, so the same sheet always
+// imports the same way. Blank rows, including rows of empty fields, are skipped.
+// A leading byte order mark is ignored.
+//
+// Errors for malformed rows give the line, column and value and wrap ErrMalformedRow.
+func ReadTable(r io.Reader, delimiter rune) (*Catalog_t, error) {
+	// spreadsheets often start the file with a byte order mark
+	br := bufio.NewReader(r)
+	if bom, err := br.Peek(len(byteOrderMark)); err == nil && string(bom) == byteOrderMark {
+		_, _ = br.Discard(len(byteOrderMark))
+	}
+	cr := csv.NewReader(br)
+	cr.Comma = delimiter
+	cr.LazyQuotes = delimiter == '\t'
+	cr.TrimLeadingSpace = delimiter != '\t'
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("header: %w", ErrMissingColumn)
+	} else if err != nil {
+		return nil, fmt.Errorf("%w: %w", err, ErrMalformedRow)
+	}
+	columns := make(map[string]int)
+	for n, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := columns[name]; ok {
+			return nil, fmt.Errorf("header: duplicate column %q: %w", name, ErrMalformedRow)
+		}
+		columns[name] = n
+	}
+	for _, name := range requiredTableColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("header: %q: %w", name, ErrMissingColumn)
+		}
+	}
+
+	c, ids := &Catalog_t{}, make(map[SystemID_t]*StarSystem_t)
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("%w: %w", err, ErrMalformedRow)
+		} else if isBlankRecord(record) {
+			continue
+		}
+		line, _ := cr.FieldPos(0)
+		row := tableRow_t{line: line, columns: columns, record: record}
+		ss, err := row.starSystem()
+		if err != nil {
+			return nil, err
+		}
+		if other, ok := ids[ss.ID]; ok {
+			return nil, fmt.Errorf("line %d: %s %q: duplicate of %s: %w", line, columnID, ss.ID, other.Designation, ErrMalformedRow)
+		}
+		ids[ss.ID] = ss
+		c.StarSystems = append(c.StarSystems, ss)
+		c.Radius = math.Max(c.Radius, ss.Coordinates.DistanceTo(Coordinates{}))
+	}
+	return c, nil
+}
+
+// byteOrderMark is the UTF-8 encoding of U+FEFF.
+const byteOrderMark = "\ufeff"
+
+// isBlankRecord returns true if every field of the record is blank.
+func isBlankRecord(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// tableRow_t is a row of a sheet being read.
+type tableRow_t struct {
+	line    int
+	columns map[string]int
+	record  []string
+}
+
+// starSystem returns the star system for the row.
+func (row tableRow_t) starSystem() (*StarSystem_t, error) {
+	ss := &StarSystem_t{
+		Designation: row.value(columnDesignation),
+		Name:        row.value(columnName),
+	}
+
+	var err error
+	if ss.Population, err = row.population(); err != nil {
+		return nil, err
+	} else if ss.Age, err = row.float(columnAge); err != nil {
+		return nil, err
+	} else if ss.Age < 0 {
+		return nil, row.errorf(columnAge, "age cannot be negative")
+	} else if ss.Coordinates.X, err = row.float(columnX); err != nil {
+		return nil, err
+	} else if ss.Coordinates.Y, err = row.float(columnY); err != nil {
+		return nil, err
+	} else if ss.Coordinates.Z, err = row.float(columnZ); err != nil {
+		return nil, err
+	} else if ss.Cluster, err = row.int(columnCluster); err != nil {
+		return nil, err
+	} else if ss.ClusterZone, err = row.clusterZone(); err != nil {
+		return nil, err
+	} else if ss.Association, err = row.int(columnAssociation); err != nil {
+		return nil, err
+	} else if ss.MassModifier, err = row.int(columnMassModifier); err != nil {
+		return nil, err
+	}
+	if ss.Cluster < 0 {
+		return nil, row.errorf(columnCluster, "cluster cannot be negative")
+	} else if ss.Association < 0 {
+		return nil, row.errorf(columnAssociation, "association cannot be negative")
+	}
+	// members of clusters and associations created on their own aren't numbered,
+	// so a blank membership column falls back to the number and the zone
+	if ss.InCluster, err = row.bool(columnInCluster, ss.Cluster != 0 || ss.ClusterZone != NoClusterZone); err != nil {
+		return nil, err
+	} else if ss.InAssociation, err = row.bool(columnInAssociation, ss.Association != 0); err != nil {
+		return nil, err
+	}
+
+	if ss.Seed, err = row.hex(columnSeed); err != nil {
+		return nil, err
+	} else if row.value(columnSeed) == "" {
+		ss.Seed = DeriveSeed(seedKeyImport, uint64(row.line))
+	}
+	if row.value(columnID) == "" {
+		ss.ID = newSystemID(ss.Seed)
+	} else if id, err := row.hex(columnID); err != nil {
+		return nil, err
+	} else {
+		ss.ID = SystemID_t(id)
+	}
+
+	ss.Generate()
+	return ss, nil
+}
+
+// value returns the trimmed value of the column, or an empty string if the sheet doesn't have the column.
+func (row tableRow_t) value(column string) string {
+	if n, ok := row.columns[column]; ok && n < len(row.record) {
+		return strings.TrimSpace(row.record[n])
+	}
+	return ""
+}
+
+// float returns the value of the column as a number. A blank value is an error.
+func (row tableRow_t) float(column string) (float64, error) {
+	f, err := strconv.ParseFloat(row.value(column), 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0, row.errorf(column, "want a number")
+	}
+	return f, nil
+}
+
+// int returns the value of the column as an integer. A blank value is zero.
+func (row tableRow_t) int(column string) (int, error) {
+	if row.value(column) == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(row.value(column))
+	if err != nil {
+		return 0, row.errorf(column, "want an integer")
+	}
+	return n, nil
+}
+
+// bool returns the value of the column as true or false. A blank value is the fallback.
+func (row tableRow_t) bool(column string, fallback bool) (bool, error) {
+	if row.value(column) == "" {
+		return fallback, nil
+	}
+	b, err := strconv.ParseBool(row.value(column))
+	if err != nil {
+		return false, row.errorf(column, "want true or false")
+	}
+	return b, nil
+}
+
+// hex returns the value of the column as a hexadecimal number. A blank value is zero.
+func (row tableRow_t) hex(column string) (uint64, error) {
+	if row.value(column) == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseUint(strings.TrimPrefix(strings.ToLower(row.value(column)), "0x"), 16, 64)
+	if err != nil {
+		return 0, row.errorf(column, "want a hexadecimal number")
+	}
+	return n, nil
+}
+
+// population returns the value of the population column. The names are not case-sensitive.
+func (row tableRow_t) population() (StellarPopulation_e, error) {
+	for _, p := range []StellarPopulation_e{YoungPopulationI, IntermediatePopulationI, OldPopulationI, DiskPopulationII, HaloPopulationII} {
+		if strings.EqualFold(row.value(columnPopulation), p.String()) {
+			return p, nil
+		}
+	}
+	return 0, row.errorf(columnPopulation, "want a stellar population like %q", YoungPopulationI.String())
+}
+
+// clusterZone returns the value of the zone column. A blank value is no zone.
+func (row tableRow_t) clusterZone() (ClusterZone_e, error) {
+	if row.value(columnClusterZone) == "" {
+		return NoClusterZone, nil
+	}
+	for _, z := range []ClusterZone_e{NoClusterZone, ClusterCoreZone, TidalRadiusZone, ExtendedHaloZone} {
+		if strings.EqualFold(row.value(columnClusterZone), z.String()) {
+			return z, nil
+		}
+	}
+	return 0, row.errorf(columnClusterZone, "want a cluster zone like %q", ClusterCoreZone.String())
+}
+
+// errorf returns an error for the value of the column that wraps ErrMalformedRow.
+func (row tableRow_t) errorf(column, format string, args ...any) error {
+	return fmt.Errorf("line %d: %s %q: %s: %w", row.line, column, row.value(column), fmt.Sprintf(format, args...), ErrMalformedRow)
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow_test
+
+import (
+	"bytes"
+	"errors"
+	"github.com/mdhender/aow"
+	"math/rand/v2"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCatalog_WriteAndReadTable(t *testing.T) {
+	g, err := aow.New(150, rand.NewPCG(0xcafe, 0xcafe), aow.SurveyCatalog, aow.WithOpenClusters(1), aow.WithStellarAssociations(1), aow.WithNameStyle("arabic"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := g.BackgroundPopulation(); err != nil {
+		t.Fatalf("BackgroundPopulation() error = %v", err)
+	}
+
+	for _, tc := range []struct {
+		name  string
+		write func(*aow.Catalog_t, *bytes.Buffer) error
+		read  func(*bytes.Buffer) (*aow.Catalog_t, error)
+	}{
+		{"csv", func(c *aow.Catalog_t, b *bytes.Buffer) error { return c.WriteCSV(b) }, func(b *bytes.Buffer) (*aow.Catalog_t, error) { return aow.ReadCSV(b) }},
+		{"tsv", func(c *aow.Catalog_t, b *bytes.Buffer) error { return c.WriteTSV(b) }, func(b *bytes.Buffer) (*aow.Catalog_t, error) { return aow.ReadTSV(b) }},
+	} {
+		var sheet bytes.Buffer
+		if err := tc.write(g.Catalog, &sheet); err != nil {
+			t.Fatalf("%s: write error = %v", tc.name, err)
+		}
+		if rows := strings.Count(sheet.String(), "\n"); rows != g.Catalog.Length()+1 {
+			t.Errorf("%s: wrote %d rows, want %d", tc.name, rows, g.Catalog.Length()+1)
+		}
+		catalog, err := tc.read(&sheet)
+		if err != nil {
+			t.Fatalf("%s: read error = %v", tc.name, err)
+		}
+		if !reflect.DeepEqual(catalog.StarSystems, g.Catalog.StarSystems) {
+			t.Errorf("%s: read star systems differ from the written catalog", tc.name)
+		}
+	}
+}
+
+func TestCatalog_WriteAndReadTableStandalone(t *testing.T) {
+	// members of clusters and associations created on their own aren't numbered
+	cluster, err := aow.NewOpenCluster(aow.NewSeededPRNG(7))
+	if err != nil {
+		t.Fatalf("NewOpenCluster() error = %v", err)
+	}
+	association, err := aow.NewStellarAssociation(aow.NewSeededPRNG(7))
+	if err != nil {
+		t.Fatalf("NewStellarAssociation() error = %v", err)
+	}
+	for name, catalog := range map[string]*aow.Catalog_t{"cluster": cluster.Catalog, "association": association.Catalog} {
+		var sheet bytes.Buffer
+		if err := catalog.WriteCSV(&sheet); err != nil {
+			t.Fatalf("%s: WriteCSV() error = %v", name, err)
+		}
+		read, err := aow.ReadCSV(&sheet)
+		if err != nil {
+			t.Fatalf("%s: ReadCSV() error = %v", name, err)
+		}
+		if !reflect.DeepEqual(read.StarSystems, catalog.StarSystems) {
+			t.Errorf("%s: ReadCSV() star systems differ from the written catalog", name)
+		}
+		for _, ss := range read.StarSystems {
+			if !aow.DefaultInterest(ss) {
+				t.Fatalf("%s: ReadCSV() %s lost its membership", name, ss.Designation)
+			}
+		}
+	}
+
+	// without the membership columns, the zone still marks a cluster member
+	read, err := aow.ReadCSV(strings.NewReader("population,age,x,y,z,cluster,zone\nyoung population I,0.1,0,0,0,0,core\n"))
+	if err != nil {
+		t.Fatalf("ReadCSV() error = %v", err)
+	}
+	if !read.StarSystems[0].InCluster {
+		t.Errorf("ReadCSV() member of the core zone isn't in a cluster")
+	}
+}
+
+func TestReadTable_HandEdited(t *testing.T) {
+	// reordered columns, a dropped summary column, extra spaces, a new system without a seed
+	sheet := `name, population, age, x, y, z, seed, stars
+Vega, Young Population I, 0.4, 1.5, -2, 0.25, 00000000000000ff, 99
+
+Home, old population I, 4.6, 0, 0, 0, ,
+`
+	catalog, err := aow.ReadCSV(strings.NewReader(sheet))
+	if err != nil {
+		t.Fatalf("ReadCSV() error = %v", err)
+	}
+	if catalog.Length() != 2 {
+		t.Fatalf("ReadCSV() read %d systems, want 2", catalog.Length())
+	}
+	vega, home := catalog.StarSystems[0], catalog.StarSystems[1]
+	if vega.Name != "Vega" || vega.Population != aow.YoungPopulationI || vega.Seed != 0xff || vega.Coordinates != (aow.Coordinates{X: 1.5, Y: -2, Z: 0.25}) {
+		t.Errorf("ReadCSV() system = %+v", vega)
+	}
+	if len(vega.Stars) == 0 || len(home.Stars) == 0 {
+		t.Errorf("ReadCSV() didn't generate the stars")
+	}
+	if home.Seed == 0 || home.ID == vega.ID {
+		t.Errorf("ReadCSV() didn't seed the new system")
+	}
+	// Excel saves "CSV UTF-8" with a byte order mark and exports blank rows as empty fields
+	excel := "\ufeff" + strings.Replace(sheet, "\n\n", "\n,,,,,,,\n", 1)
+	if exported, err := aow.ReadCSV(strings.NewReader(excel)); err != nil {
+		t.Errorf("ReadCSV() with a byte order mark and empty fields error = %v", err)
+	} else if exported.Length() != 2 {
+		t.Errorf("ReadCSV() with a byte order mark and empty fields read %d systems, want 2", exported.Length())
+	}
+	if exported, err := aow.ReadTSV(strings.NewReader("\ufeffpopulation\tage\tx\ty\tz\n\t\t\t\t\nyoung population I\t1\t0\t0\t0\n")); err != nil {
+		t.Errorf("ReadTSV() with a byte order mark and empty fields error = %v", err)
+	} else if exported.Length() != 1 {
+		t.Errorf("ReadTSV() with a byte order mark and empty fields read %d systems, want 1", exported.Length())
+	}
+
+	again, err := aow.ReadCSV(strings.NewReader(sheet))
+	if err != nil {
+		t.Fatalf("ReadCSV() error = %v", err)
+	}
+	if !reflect.DeepEqual(again.StarSystems, catalog.StarSystems) {
+		t.Errorf("ReadCSV() is not repeatable")
+	}
+}
+
+func TestReadTable_Errors(t *testing.T) {
+	header := "id,population,age,x,y,z,cluster,zone,seed\n"
+	for _, tc := range []struct {
+		name     string
+		sheet    string
+		expected error
+		message  string
+	}{
+		{"empty", ``, aow.ErrMissingColumn, "header"},
+		{"missing column", "population,age,x,y\n", aow.ErrMissingColumn, `"z"`},
+		{"duplicate column", "population,age,x,y,z,x\n", aow.ErrMalformedRow, `duplicate column "x"`},
+		{"population", header + ",population III,1,0,0,0,,,\n", aow.ErrMalformedRow, `line 2: population "population III"`},
+		{"age", header + ",young population I,old,0,0,0,,,\n", aow.ErrMalformedRow, `line 2: age "old"`},
+		{"negative age", header + ",young population I,-1,0,0,0,,,\n", aow.ErrMalformedRow, `line 2: age "-1"`},
+		{"blank coordinate", header + ",young population I,1,0,,0,,,\n", aow.ErrMalformedRow, `line 2: y ""`},
+		{"cluster", header + ",young population I,1,0,0,0,one,,\n", aow.ErrMalformedRow, `line 2: cluster "one"`},
+		{"zone", header + ",young population I,1,0,0,0,1,center,\n", aow.ErrMalformedRow, `line 2: zone "center"`},
+		{"seed", header + ",young population I,1,0,0,0,,,xyz\n", aow.ErrMalformedRow, `line 2: seed "xyz"`},
+		{"membership", "population,age,x,y,z,in_cluster\nyoung population I,1,0,0,0,maybe\n", aow.ErrMalformedRow, `line 2: in_cluster "maybe"`},
+		{"field count", header + ",young population I,1,0,0,0\n", aow.ErrMalformedRow, "line 2"},
+		{"duplicate id", header + "1,young population I,1,0,0,0,,,\n1,young population I,1,0,0,0,,,\n", aow.ErrMalformedRow, "line 3: id"},
+	} {
+		_, err := aow.ReadCSV(strings.NewReader(tc.sheet))
+		if !errors.Is(err, tc.expected) {
+			t.Errorf("%s: ReadCSV() error = %v, want %v", tc.name, err, tc.expected)
+		} else if !strings.Contains(err.Error(), tc.message) {
+			t.Errorf("%s: ReadCSV() error = %q, want it to mention %q", tc.name, err, tc.message)
+		}
+	}
+}
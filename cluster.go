@@ -0,0 +1,223 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow
+
+import (
+	"fmt"
+	"log"
+	"math"
+)
+
+// Cluster_t is an open cluster: a group of star systems that formed together
+// from the same cloud and are still loosely held together by their mutual gravity.
+type Cluster_t struct {
+	ID           int                 // the number of the cluster in the generator; 0 if it was created on its own
+	Age          float64             // in billions of years
+	TightlyBound bool                // tightly bound clusters evaporate more slowly
+	Population   StellarPopulation_e // the population of every member
+	Radius       float64             // radius of the extended halo, in parsecs
+	Center       Coordinates         // relative to the center of the map
+	CoreCount    int                 // number of members in the catalog from the core zone
+	TidalCount   int                 // number of members in the catalog from the tidal radius zone
+	HaloCount    int                 // number of members in the catalog from the extended halo zone
+	Catalog      *Catalog_t          // the members, with coordinates relative to the center of the cluster
+}
+
+// ClusterZone_e is the zone of a cluster that a member was generated in.
+type ClusterZone_e int
+
+const (
+	// NoClusterZone is used for systems that aren't in a cluster.
+	NoClusterZone ClusterZone_e = iota
+	ClusterCoreZone
+	TidalRadiusZone
+	ExtendedHaloZone
+)
+
+// String implements the Stringer interface.
+func (z ClusterZone_e) String() string {
+	switch z {
+	case NoClusterZone:
+		return "none"
+	case ClusterCoreZone:
+		return "core"
+	case TidalRadiusZone:
+		return "tidal radius"
+	case ExtendedHaloZone:
+		return "extended halo"
+	}
+	return fmt.Sprintf("ClusterZone_e(%d)", int(z))
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (z ClusterZone_e) MarshalText() ([]byte, error) {
+	switch z {
+	case NoClusterZone, ClusterCoreZone, TidalRadiusZone, ExtendedHaloZone:
+		return []byte(z.String()), nil
+	}
+	return nil, fmt.Errorf("cluster zone %d: %w", int(z), ErrInvalidCatalog)
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (z *ClusterZone_e) UnmarshalText(text []byte) error {
+	for _, value := range []ClusterZone_e{NoClusterZone, ClusterCoreZone, TidalRadiusZone, ExtendedHaloZone} {
+		if string(text) == value.String() {
+			*z = value
+			return nil
+		}
+	}
+	return fmt.Errorf("cluster zone %q: %w", string(text), ErrInvalidCatalog)
+}
+
+const (
+	minPctClusterCoreZone  float64 = 0.0
+	maxPctClusterCoreZone  float64 = 0.05
+	minPctTidalRadiusZone  float64 = 0.05
+	maxPctTidalRadiusZone  float64 = 0.2
+	minPctExtendedHaloZone float64 = 0.2
+	maxPctExtendedHaloZone float64 = 1.0
+)
+
+// NewOpenCluster creates an open cluster centered on the origin, using the tables
+// from the book. The first draw from the PRNG is the seed of the cluster's catalog.
+func NewOpenCluster(prng PRNG) (*Cluster_t, error) {
+	return newOpenCluster(builtinTables(), prng)
+}
+
+// newOpenCluster implements NewOpenCluster with the given tables.
+func newOpenCluster(tables *Tables_t, prng PRNG) (*Cluster_t, error) {
+	seed := prng.WithLabel("catalog seed").Uint64()
+
+	// cluster can be tightly or loosely bound.
+	isTightlyBound := prng.WithLabel("cluster binding").RollD6(3) <= 5
+
+	// determine the age of the cluster (in billions of years)
+	ageTable := &tables.LooselyBoundClusterAge
+	if isTightlyBound {
+		ageTable = &tables.TightlyBoundClusterAge
+	}
+	agePRNG := prng.WithLabel("cluster age table")
+	clusterAge := ageTable.Roll(agePRNG, 0).Place(agePRNG.RollPercentile())
+
+	// population group depends on the age of the cluster
+	var stpop StellarPopulation_e
+	if clusterAge < 2.0 {
+		stpop = YoungPopulationI
+	} else if clusterAge < 5.0 {
+		stpop = IntermediatePopulationI
+	} else {
+		stpop = OldPopulationI
+	}
+
+	// generate the initial radius (in parsecs), give or take 0.25 parsecs
+	radiusPRNG := prng.WithLabel("cluster radius")
+	clusterRadius := radiusPRNG.RollD6(2) / 2
+	log.Printf("cluster radius: %f\n", clusterRadius)
+	clusterRadius += (radiusPRNG.VaryNPct(1.0, 0.25) - 1)
+	log.Printf("cluster radius: %f\n", clusterRadius)
+
+	// initial number of star systems in the cluster
+	numberOfStarSystems := prng.WithLabel("cluster size").RollD6(2) / 2
+	if isTightlyBound && numberOfStarSystems < 3.5 {
+		numberOfStarSystems = 3.5
+	}
+	numberOfStarSystems = math.Floor(numberOfStarSystems * math.Pow(clusterRadius, 3))
+	log.Printf("number of star systems: %f\n", numberOfStarSystems)
+
+	// determine the effective age of the cluster for the evaporation table
+	effectiveClusterAge := clusterAge
+	if isTightlyBound {
+		effectiveClusterAge = clusterAge / 10
+	}
+	log.Printf("effective cluster age: %f\n", effectiveClusterAge)
+
+	// use the cluster evaporation table to get radius (as a percentage) for each zone.
+	// the table is indexed by the effective age in tenths of a billion years.
+	zones := tables.ClusterEvaporation.Lookup(int(math.Floor(effectiveClusterAge * 10)))
+	corePct, tidalPct, extendedHaloPct := zones.Core, zones.Tidal, zones.Halo
+	log.Printf("core: %f, tidal: %f, extended halo: %f\n", corePct, tidalPct, extendedHaloPct)
+	coreCount, tidalCount, extendedHaloCount := int(corePct*numberOfStarSystems), int(tidalPct*numberOfStarSystems), int(extendedHaloPct*numberOfStarSystems)
+	log.Printf("core count: %d, title count: %d, extended halo count: %d\n", coreCount, tidalCount, extendedHaloCount)
+	if float64(coreCount+tidalCount+extendedHaloCount) < numberOfStarSystems {
+		coreCount++
+		if float64(coreCount+tidalCount+extendedHaloCount) < numberOfStarSystems {
+			tidalCount++
+		}
+	}
+	log.Printf("core count: %d, title count: %d, extended halo count: %d\n", coreCount, tidalCount, extendedHaloCount)
+
+	// we have the information needed to create the cluster
+	cluster := &Cluster_t{
+		Age:          clusterAge,
+		TightlyBound: isTightlyBound,
+		Population:   stpop,
+		Radius:       clusterRadius,
+		CoreCount:    coreCount,
+		TidalCount:   tidalCount,
+		HaloCount:    extendedHaloCount,
+		Catalog:      &Catalog_t{Seed: seed, Radius: clusterRadius},
+	}
+
+	// create the star systems in each zone, from the core outwards
+	for _, zone := range []struct {
+		zone           ClusterZone_e
+		count          int
+		minPct, maxPct float64
+	}{
+		{zone: ClusterCoreZone, count: coreCount, minPct: minPctClusterCoreZone, maxPct: maxPctClusterCoreZone},
+		{zone: TidalRadiusZone, count: tidalCount, minPct: minPctTidalRadiusZone, maxPct: maxPctTidalRadiusZone},
+		{zone: ExtendedHaloZone, count: extendedHaloCount, minPct: minPctExtendedHaloZone, maxPct: maxPctExtendedHaloZone},
+	} {
+		log.Printf("gen %v %f %f %d/%d\n", zone.zone, zone.minPct, zone.maxPct, zone.count, int(numberOfStarSystems))
+		for ; zone.count > 0; zone.count-- {
+			ss := &StarSystem_t{
+				Population: stpop,
+				// generate a random age for the star system
+				Age: prng.WithLabel("system age").Vary5Pct(clusterAge),
+				// generate a random position for the star system
+				Coordinates: prng.WithLabel("system coordinates").GenZonedXYZ(zone.minPct, zone.maxPct).Scale(clusterRadius),
+				InCluster:   true,
+				ClusterZone: zone.zone,
+			}
+			// generate the stars and planets in the system from its own seed
+			ss.Seed = DeriveSeed(cluster.Catalog.Seed, uint64(len(cluster.Catalog.StarSystems)))
+			ss.ID, ss.Designation = newSystemID(ss.Seed), clusterDesignation(cluster.ID, cluster.Catalog.Seed, len(cluster.Catalog.StarSystems)+1)
+			ss.generate(prng)
+			cluster.Catalog.StarSystems = append(cluster.Catalog.StarSystems, ss)
+		}
+	}
+
+	return cluster, nil
+}
+
+// MoveTo places the cluster at the center. The coordinates of the members
+// stay relative to the center of the cluster.
+func (c *Cluster_t) MoveTo(center Coordinates) {
+	c.Center = center
+	c.Catalog.Coordinates = center
+}
+
+// recount counts the members in each zone again after the catalog has been filtered.
+func (c *Cluster_t) recount() {
+	c.CoreCount, c.TidalCount, c.HaloCount = 0, 0, 0
+	for _, ss := range c.Catalog.StarSystems {
+		switch ss.ClusterZone {
+		case ClusterCoreZone:
+			c.CoreCount++
+		case TidalRadiusZone:
+			c.TidalCount++
+		case ExtendedHaloZone:
+			c.HaloCount++
+		}
+	}
+}
+
+// setID numbers the cluster and tags every member with the number.
+// The designations of the members include the number of the cluster.
+func (c *Cluster_t) setID(id int) {
+	c.ID = id
+	for n, ss := range c.Catalog.StarSystems {
+		ss.Cluster, ss.Designation = id, clusterDesignation(id, c.Catalog.Seed, n+1)
+	}
+	c.Catalog.Reindex()
+}
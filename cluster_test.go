@@ -0,0 +1,120 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow_test
+
+import (
+	"github.com/mdhender/aow"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestNewOpenCluster(t *testing.T) {
+	for seed := uint64(1); seed <= 20; seed++ {
+		cluster, err := aow.NewOpenCluster(aow.NewSeededPRNG(seed))
+		if err != nil {
+			t.Fatalf("NewOpenCluster() error = %v", err)
+		}
+		if n := cluster.CoreCount + cluster.TidalCount + cluster.HaloCount; n != cluster.Catalog.Length() {
+			t.Errorf("%d: NewOpenCluster() counts total %d, want %d members", seed, n, cluster.Catalog.Length())
+		}
+		if cluster.Catalog.Radius != cluster.Radius {
+			t.Errorf("%d: NewOpenCluster() catalog radius %f, want %f", seed, cluster.Catalog.Radius, cluster.Radius)
+		}
+		if cluster.Center != (aow.Coordinates{}) {
+			t.Errorf("%d: NewOpenCluster() center %v, want the origin", seed, cluster.Center)
+		}
+		if cluster.TightlyBound && cluster.Catalog.Length() < 3 {
+			t.Errorf("%d: NewOpenCluster() tightly bound cluster has %d members", seed, cluster.Catalog.Length())
+		}
+		counts := make(map[aow.ClusterZone_e]int)
+		for _, ss := range cluster.Catalog.StarSystems {
+			counts[ss.ClusterZone]++
+			if !ss.InCluster || ss.Population != cluster.Population {
+				t.Errorf("%d: NewOpenCluster() member in cluster %v population %v, want true %v", seed, ss.InCluster, ss.Population, cluster.Population)
+			}
+			maxPct := map[aow.ClusterZone_e]float64{aow.ClusterCoreZone: 0.05, aow.TidalRadiusZone: 0.2, aow.ExtendedHaloZone: 1.0}[ss.ClusterZone]
+			if d := ss.Coordinates.DistanceTo(aow.Coordinates{}); d > maxPct*cluster.Radius+0.000_001 {
+				t.Errorf("%d: NewOpenCluster() %v member at %f, want within %f", seed, ss.ClusterZone, d, maxPct*cluster.Radius)
+			}
+		}
+		if counts[aow.ClusterCoreZone] != cluster.CoreCount || counts[aow.TidalRadiusZone] != cluster.TidalCount || counts[aow.ExtendedHaloZone] != cluster.HaloCount {
+			t.Errorf("%d: NewOpenCluster() members by zone %v, want %d %d %d", seed, counts, cluster.CoreCount, cluster.TidalCount, cluster.HaloCount)
+		}
+	}
+}
+
+func TestNewOpenCluster_Designations(t *testing.T) {
+	// clusters created on their own aren't numbered, but their designations don't collide
+	designations := make(map[string]bool)
+	for seed := uint64(1); seed <= 20; seed++ {
+		cluster, err := aow.NewOpenCluster(aow.NewSeededPRNG(seed))
+		if err != nil {
+			t.Fatalf("NewOpenCluster() error = %v", err)
+		}
+		for _, ss := range cluster.Catalog.StarSystems {
+			if designations[ss.Designation] {
+				t.Fatalf("%d: NewOpenCluster() duplicate designation %q", seed, ss.Designation)
+			}
+			designations[ss.Designation] = true
+		}
+	}
+}
+
+func TestGenerator_OpenClusterWithInterest(t *testing.T) {
+	// the zone counts match the members kept in a reference catalog
+	g, err := aow.New(100, rand.NewPCG(0xcafe, 0xcafe), aow.ReferenceCatalog, aow.WithInterest(aow.IsBrightStar))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	for n := 0; n < 10; n++ {
+		cluster, err := g.OpenCluster(aow.Coordinates{})
+		if err != nil {
+			t.Fatalf("OpenCluster() error = %v", err)
+		}
+		if n := cluster.CoreCount + cluster.TidalCount + cluster.HaloCount; n != cluster.Catalog.Length() {
+			t.Errorf("OpenCluster() counts total %d, want %d members", n, cluster.Catalog.Length())
+		}
+	}
+}
+
+func TestGenerator_OpenCluster(t *testing.T) {
+	g, err := aow.New(100, rand.NewPCG(0xcafe, 0xcafe), aow.SurveyCatalog)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := g.BackgroundPopulation(); err != nil {
+		t.Fatalf("BackgroundPopulation() error = %v", err)
+	}
+	background := g.Catalog.Length()
+
+	for n, origin := range []aow.Coordinates{{X: 5, Y: -3, Z: 1}, {X: -4, Y: 2, Z: 0}} {
+		cluster, err := g.OpenCluster(origin)
+		if err != nil {
+			t.Fatalf("OpenCluster() error = %v", err)
+		}
+		if cluster.ID != n+1 || cluster.Center != origin || cluster.Catalog.Coordinates != origin {
+			t.Errorf("OpenCluster() id %d center %v, want %d %v", cluster.ID, cluster.Center, n+1, origin)
+		}
+		g.Catalog.MergeCluster(cluster)
+	}
+	if len(g.Clusters) != 2 {
+		t.Fatalf("Generator has %d clusters, want 2", len(g.Clusters))
+	}
+
+	members := 0
+	for _, ss := range g.Catalog.StarSystems[background:] {
+		cluster := g.Clusters[ss.Cluster-1]
+		if d := ss.Coordinates.DistanceTo(cluster.Center); d > cluster.Radius+0.000_001 {
+			t.Errorf("MergeCluster() member of cluster %d is %f from the center, want within %f", ss.Cluster, d, cluster.Radius)
+		}
+		members++
+	}
+	if expected := g.Clusters[0].Catalog.Length() + g.Clusters[1].Catalog.Length(); members != expected {
+		t.Errorf("MergeCluster() added %d members, want %d", members, expected)
+	}
+	for _, ss := range g.Catalog.StarSystems[:background] {
+		if ss.Cluster != 0 || ss.ClusterZone != aow.NoClusterZone {
+			t.Errorf("background system tagged with cluster %d zone %v", ss.Cluster, ss.ClusterZone)
+		}
+	}
+}
@@ -21,7 +21,12 @@ func main() {
 func run(addCluster bool) error {
 	// create a generator for Bob's map.
 	// Bob wants at least 40 Earth-like systems.
-	g, err := aow.New(1000, aow.NewPRNG(rand.NewPCG(0xcafe, 0xcafe)), aow.ReferenceCatalog)
+	// the generator seeds clusters and associations on its own; Bob's map has exactly one cluster.
+	clusters := 0
+	if addCluster {
+		clusters = 1
+	}
+	g, err := aow.New(40, aow.NewPRNG(rand.NewPCG(0xcafe, 0xcafe)), aow.ReferenceCatalog, aow.WithEarthLikeSystems(), aow.WithOpenClusters(clusters), aow.WithNameStyle("classical"))
 	if err != nil {
 		return err
 	}
@@ -34,18 +39,12 @@ func run(addCluster bool) error {
 		return err
 	}
 	log.Printf("g: %d star systems, %f radius", g.Catalog.Length(), g.Catalog.Radius)
-	if addCluster {
-		clusterCenter := g.GenZonedXYZ(0.77, 0.89)
-		log.Printf("x %g y %g z %g r %g %8.4f\n", clusterCenter.X, clusterCenter.Y, clusterCenter.Z, g.Radius, origin.DistanceTo(clusterCenter))
-		clusterCatalog, err := g.OpenCluster(clusterCenter)
-		if err != nil {
-			return err
-		}
-		g.Catalog.Merge(clusterCatalog, clusterCenter)
+	for _, cluster := range g.Clusters {
+		log.Printf("cluster %d: center %s delta %8.4f age %6.2f radius %6.2f members %d\n", cluster.ID, cluster.Center, origin.DistanceTo(cluster.Center), cluster.Age, cluster.Radius, cluster.Catalog.Length())
 	}
 	g.Catalog.SortByDistance(origin)
 	for n, ss := range g.Catalog.StarSystems {
-		log.Printf("%4d: ss pop %v age %6.2f delta %8.4f %s\n", n+1, ss.Population, ss.Age, origin.DistanceTo(ss.Coordinates), ss.Coordinates)
+		log.Printf("%4d: %-12s %-12s ss pop %v age %6.2f delta %8.4f %s\n", n+1, ss.Designation, ss.Name, ss.Population, ss.Age, origin.DistanceTo(ss.Coordinates), ss.Coordinates)
 	}
 
 	return nil
@@ -0,0 +1,271 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow
+
+import "fmt"
+
+// SeparationClass_e is the classification of the separation between a companion
+// star and the star (or pair of stars) that it orbits.
+type SeparationClass_e int
+
+const (
+	// NoSeparation is used for the primary star, which doesn't orbit anything.
+	NoSeparation SeparationClass_e = iota
+	VeryCloseSeparation
+	CloseSeparation
+	ModerateSeparation
+	WideSeparation
+	DistantSeparation
+)
+
+// String implements the Stringer interface.
+func (s SeparationClass_e) String() string {
+	switch s {
+	case NoSeparation:
+		return "none"
+	case VeryCloseSeparation:
+		return "very close"
+	case CloseSeparation:
+		return "close"
+	case ModerateSeparation:
+		return "moderate"
+	case WideSeparation:
+		return "wide"
+	case DistantSeparation:
+		return "distant"
+	}
+	return fmt.Sprintf("SeparationClass_e(%d)", int(s))
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (s SeparationClass_e) MarshalText() ([]byte, error) {
+	switch s {
+	case NoSeparation, VeryCloseSeparation, CloseSeparation, ModerateSeparation, WideSeparation, DistantSeparation:
+		return []byte(s.String()), nil
+	}
+	return nil, fmt.Errorf("separation class %d: %w", int(s), ErrInvalidCatalog)
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (s *SeparationClass_e) UnmarshalText(text []byte) error {
+	for _, value := range []SeparationClass_e{NoSeparation, VeryCloseSeparation, CloseSeparation, ModerateSeparation, WideSeparation, DistantSeparation} {
+		if string(text) == value.String() {
+			*s = value
+			return nil
+		}
+	}
+	return fmt.Errorf("separation class %q: %w", string(text), ErrInvalidCatalog)
+}
+
+// StellarOrbit_t is the orbit of a companion star.
+// The first companion orbits the primary; the second companion of a triple orbits the inner pair.
+type StellarOrbit_t struct {
+	Separation    SeparationClass_e `json:"separation"`    // classification of the separation
+	Radius        float64           `json:"radius"`        // average separation, in AU
+	Eccentricity  float64           `json:"eccentricity"`  // eccentricity of the orbit
+	ForbiddenZone Zone_t            `json:"forbiddenZone"` // planetary orbits in this zone are unstable
+}
+
+// Periapsis returns the closest approach (in AU) of the companion.
+func (o StellarOrbit_t) Periapsis() float64 {
+	return o.Radius * (1 - o.Eccentricity)
+}
+
+// Apoapsis returns the furthest separation (in AU) of the companion.
+func (o StellarOrbit_t) Apoapsis() float64 {
+	return o.Radius * (1 + o.Eccentricity)
+}
+
+// Zone_t is a range of distances (in AU) from a star.
+type Zone_t struct {
+	Inner float64 `json:"inner"`
+	Outer float64 `json:"outer"`
+}
+
+// Contains returns true if the distance is within the zone.
+func (z Zone_t) Contains(d float64) bool {
+	return z.Inner <= d && d <= z.Outer
+}
+
+// NewStars generates the stars in a star system. The first star is always the primary.
+// Companions follow in order of increasing separation.
+//
+// Parameters:
+//   - age: The age of the star system, in billions of years.
+//   - prng: The source of random numbers.
+func NewStars(age float64, prng PRNG) []Star_t {
+	return newStars(age, 0, prng)
+}
+
+// newStars implements NewStars. The modifier is added to the stellar mass roll of the primary.
+func newStars(age float64, modifier int, prng PRNG) []Star_t {
+	stars := []Star_t{newPrimaryStar(age, modifier, prng)}
+	primary := stars[0]
+
+	// the number of companions depends on the mass of the primary
+	numberOfCompanions := NumberOfCompanions(primary, prng)
+
+	for n := 1; n <= numberOfCompanions; n++ {
+		companion := NewCompanionStar(primary, prng)
+
+		// the outer companion of a triple orbits the inner pair
+		separationModifier := 0
+		if n > 1 {
+			separationModifier = 6
+		}
+		companion.Orbit = newStellarOrbit(separationModifier, prng)
+
+		if n > 1 {
+			// keep the hierarchy stable by pushing the outer companion beyond the forbidden zone of the inner pair
+			inner := stars[n-1].Orbit
+			if minPeriapsis := 3 * inner.Apoapsis(); companion.Orbit.Periapsis() < minPeriapsis {
+				companion.Orbit.Radius = minPeriapsis / (1 - companion.Orbit.Eccentricity)
+				companion.Orbit.Separation = separationClassOf(companion.Orbit.Radius)
+				companion.Orbit.ForbiddenZone = forbiddenZone(companion.Orbit)
+			}
+		}
+
+		stars = append(stars, companion)
+	}
+
+	// all the stars in the system formed at the same time
+	for i := range stars {
+		stars[i].State = StellarEvolution(stars[i].Mass, age)
+	}
+
+	return stars
+}
+
+// NumberOfCompanions returns the number of companions (0, 1 or 2) for the primary star.
+// More massive stars are more likely to have companions.
+func NumberOfCompanions(primary Star_t, prng PRNG) int {
+	var modifier float64
+	switch {
+	case primary.BrownDwarf:
+		modifier = -4
+	case primary.Mass < 0.5:
+		modifier = -2
+	case primary.Mass < 1.0:
+		modifier = 0
+	case primary.Mass < 1.5:
+		modifier = 1
+	default:
+		modifier = 3
+	}
+	switch roll := prng.WithLabel("multiplicity").RollD6(3) + modifier; {
+	case roll <= 10:
+		return 0
+	case roll <= 15:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// NewCompanionStar generates a companion star with a mass determined by the mass ratio table.
+// The orbit of the companion is not set.
+func NewCompanionStar(primary Star_t, prng PRNG) Star_t {
+	prng = prng.WithLabel("companion mass ratio")
+	var minRatio, maxRatio float64
+	switch roll := prng.RollD6(3); {
+	case roll <= 5:
+		minRatio, maxRatio = 0.90, 1.00
+	case roll <= 8:
+		minRatio, maxRatio = 0.70, 0.90
+	case roll <= 11:
+		minRatio, maxRatio = 0.45, 0.70
+	case roll <= 14:
+		minRatio, maxRatio = 0.25, 0.45
+	default:
+		minRatio, maxRatio = 0.05, 0.25
+	}
+	mass := primary.Mass * (minRatio + (maxRatio-minRatio)*prng.RollPercentile())
+	if mass < 0.015 {
+		mass = 0.015
+	}
+	return Star_t{
+		Mass:       mass,
+		BrownDwarf: mass < brownDwarfMassLimit,
+	}
+}
+
+// newStellarOrbit generates the orbit of a companion star.
+// The modifier is added to the separation roll.
+func newStellarOrbit(modifier int, prng PRNG) StellarOrbit_t {
+	var o StellarOrbit_t
+
+	// determine the separation class and the average separation
+	roll := int(prng.WithLabel("stellar separation").RollD6(3)) + modifier
+	for _, row := range separationTable {
+		if row.separation == NoSeparation {
+			continue
+		} else if roll <= row.roll {
+			o.Separation = row.separation
+			break
+		}
+	}
+	o.Radius = separationTable[o.Separation].multiplier * prng.WithLabel("stellar separation").RollD6(2)
+
+	// closer companions have more circular orbits
+	eroll := int(prng.WithLabel("stellar eccentricity").RollD6(3)) + separationTable[o.Separation].eccentricityModifier
+	for _, row := range stellarEccentricityTable {
+		if eroll <= row.roll {
+			o.Eccentricity = row.eccentricity
+			break
+		}
+	}
+
+	o.ForbiddenZone = forbiddenZone(o)
+
+	return o
+}
+
+// separationClassOf returns the separation class for an average separation (in AU).
+func separationClassOf(radius float64) SeparationClass_e {
+	for _, row := range separationTable {
+		if row.separation != NoSeparation && radius <= row.multiplier*12 {
+			return row.separation
+		}
+	}
+	return DistantSeparation
+}
+
+// forbiddenZone returns the zone where planetary orbits are unstable because of a companion.
+// Planets can't orbit further out than one-third of the periapsis, or closer than three times the apoapsis.
+func forbiddenZone(o StellarOrbit_t) Zone_t {
+	return Zone_t{Inner: o.Periapsis() / 3, Outer: o.Apoapsis() * 3}
+}
+
+// separationTable is indexed by SeparationClass_e.
+// The roll is on 3d6; the average separation is the multiplier times 2d6 AU.
+var separationTable = []struct {
+	separation           SeparationClass_e
+	roll                 int
+	multiplier           float64
+	eccentricityModifier int
+}{
+	{separation: NoSeparation},
+	{separation: VeryCloseSeparation, roll: 6, multiplier: 0.05, eccentricityModifier: -6},
+	{separation: CloseSeparation, roll: 9, multiplier: 0.5, eccentricityModifier: -4},
+	{separation: ModerateSeparation, roll: 11, multiplier: 2, eccentricityModifier: -2},
+	{separation: WideSeparation, roll: 14, multiplier: 10, eccentricityModifier: 0},
+	{separation: DistantSeparation, roll: 999, multiplier: 50, eccentricityModifier: 0},
+}
+
+// stellarEccentricityTable is the 3d6 table for the eccentricity of companion orbits.
+var stellarEccentricityTable = []struct {
+	roll         int
+	eccentricity float64
+}{
+	{roll: 3, eccentricity: 0.0},
+	{roll: 4, eccentricity: 0.1},
+	{roll: 5, eccentricity: 0.2},
+	{roll: 6, eccentricity: 0.3},
+	{roll: 8, eccentricity: 0.4},
+	{roll: 11, eccentricity: 0.5},
+	{roll: 13, eccentricity: 0.6},
+	{roll: 15, eccentricity: 0.7},
+	{roll: 16, eccentricity: 0.8},
+	{roll: 17, eccentricity: 0.9},
+	{roll: 999, eccentricity: 0.95},
+}
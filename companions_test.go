@@ -0,0 +1,43 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow_test
+
+import (
+	"github.com/mdhender/aow"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestNewStars(t *testing.T) {
+	p := aow.NewPRNG(rand.NewPCG(0xcafe, 0xcafe)) // Use a fixed seed for reproducibility
+	counts := make(map[int]int)
+	for n := 0; n < 1_000; n++ {
+		stars := aow.NewStars(4.6, p)
+		counts[len(stars)]++
+		if len(stars) < 1 || len(stars) > 3 {
+			t.Fatalf("NewStars() returned %d stars, want between 1 and 3", len(stars))
+		}
+		if !stars[0].IsPrimary() {
+			t.Errorf("NewStars() first star is not the primary")
+		}
+		for i, star := range stars[1:] {
+			if star.IsPrimary() {
+				t.Errorf("NewStars() companion %d has no orbit", i+1)
+			}
+			if star.Mass > stars[0].Mass {
+				t.Errorf("NewStars() companion %d mass %f exceeds primary mass %f", i+1, star.Mass, stars[0].Mass)
+			}
+			if zone := star.Orbit.ForbiddenZone; zone.Inner > star.Orbit.Periapsis() || zone.Outer < star.Orbit.Apoapsis() {
+				t.Errorf("NewStars() companion %d forbidden zone %+v does not cover orbit %+v", i+1, zone, star.Orbit)
+			}
+		}
+		if len(stars) == 3 && stars[2].Orbit.Periapsis() < 0.999_999*3*stars[1].Orbit.Apoapsis() {
+			t.Errorf("NewStars() outer companion periapsis %f inside inner pair's forbidden zone %f", stars[2].Orbit.Periapsis(), 3*stars[1].Orbit.Apoapsis())
+		}
+	}
+	for _, n := range []int{1, 2, 3} {
+		if counts[n] == 0 {
+			t.Errorf("NewStars() generated no systems with %d stars", n)
+		}
+	}
+}
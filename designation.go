@@ -0,0 +1,108 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow
+
+import "fmt"
+
+// SystemID_t is a stable identifier for a star system. It is derived from the seed
+// of the system when the system is generated, so it doesn't change when a catalog
+// is sorted, filtered or merged, and the same generator always assigns the same IDs.
+type SystemID_t uint64
+
+// String implements the Stringer interface.
+func (id SystemID_t) String() string {
+	return fmt.Sprintf("%016x", uint64(id))
+}
+
+// designationPrefix starts the designation of every star system.
+const designationPrefix = "AOW"
+
+// newSystemID returns the ID for the system with the seed.
+func newSystemID(seed uint64) SystemID_t {
+	return SystemID_t(DeriveSeed(seed, seedKeyID))
+}
+
+// backgroundDesignation returns the designation of the n'th system (counting from 1)
+// of the background population, like "AOW 0042".
+func backgroundDesignation(n int) string {
+	return fmt.Sprintf("%s %04d", designationPrefix, n)
+}
+
+// clusterDesignation returns the designation of the n'th member (counting from 1)
+// of the cluster, like "AOW C1-0007". Clusters created on their own aren't numbered,
+// so their members are designated by the seed of the cluster instead, like "AOW C#9f3a12bc-0007".
+func clusterDesignation(cluster int, seed uint64, n int) string {
+	if cluster == 0 {
+		return fmt.Sprintf("%s C#%08x-%04d", designationPrefix, seed>>32, n)
+	}
+	return fmt.Sprintf("%s C%d-%04d", designationPrefix, cluster, n)
+}
+
+// associationDesignation returns the designation of the n'th member (counting from 1)
+// of the stellar association, like "AOW A1-0012". Associations created on their own aren't
+// numbered, so their members are designated by the seed of the association instead, like "AOW A#9f3a12bc-0012".
+func associationDesignation(association int, seed uint64, n int) string {
+	if association == 0 {
+		return fmt.Sprintf("%s A#%08x-%04d", designationPrefix, seed>>32, n)
+	}
+	return fmt.Sprintf("%s A%d-%04d", designationPrefix, association, n)
+}
+
+// Lookup returns the star system with the ID, or nil if it isn't in the catalog.
+func (c *Catalog_t) Lookup(id SystemID_t) *StarSystem_t {
+	return c.lookupIndex().byID[id]
+}
+
+// LookupDesignation returns the star system with the designation, or nil if it isn't in the catalog.
+func (c *Catalog_t) LookupDesignation(designation string) *StarSystem_t {
+	return c.lookupIndex().byDesignation[designation]
+}
+
+// lookupIndex_t maps the IDs and designations of the systems in a catalog to the systems.
+type lookupIndex_t struct {
+	byID          map[SystemID_t]*StarSystem_t
+	byDesignation map[string]*StarSystem_t
+}
+
+// lookupIndex returns the lookup index for the catalog, building it if needed.
+// The index is kept until Reindex is called. If two systems share an ID or a
+// designation, the first one in the catalog is found.
+func (c *Catalog_t) lookupIndex() *lookupIndex_t {
+	if c.lookup == nil {
+		c.lookup = &lookupIndex_t{
+			byID:          make(map[SystemID_t]*StarSystem_t, len(c.StarSystems)),
+			byDesignation: make(map[string]*StarSystem_t, len(c.StarSystems)),
+		}
+		for _, ss := range c.StarSystems {
+			c.lookup.add(ss)
+		}
+	}
+	return c.lookup
+}
+
+// add adds the system to the index unless the ID or designation is already taken.
+func (l *lookupIndex_t) add(ss *StarSystem_t) {
+	if _, ok := l.byID[ss.ID]; !ok {
+		l.byID[ss.ID] = ss
+	}
+	if _, ok := l.byDesignation[ss.Designation]; !ok {
+		l.byDesignation[ss.Designation] = ss
+	}
+}
+
+// unique changes the ID and designation of the system, if needed, so that they
+// aren't taken by a system in the index. A new ID is derived from the old one, and
+// a new designation has a copy number, like "AOW C1-0007/2".
+func (l *lookupIndex_t) unique(ss *StarSystem_t) {
+	for l.byID[ss.ID] != nil {
+		ss.ID = SystemID_t(DeriveSeed(uint64(ss.ID), seedKeyID))
+	}
+	if l.byDesignation[ss.Designation] != nil {
+		for n := 2; ; n++ {
+			if designation := fmt.Sprintf("%s/%d", ss.Designation, n); l.byDesignation[designation] == nil {
+				ss.Designation = designation
+				break
+			}
+		}
+	}
+}
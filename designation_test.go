@@ -0,0 +1,148 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow_test
+
+import (
+	"github.com/mdhender/aow"
+	"math/rand/v2"
+	"regexp"
+	"testing"
+)
+
+func TestCatalog_IDsAndDesignations(t *testing.T) {
+	generate := func() *aow.Generator {
+		g, err := aow.New(200, rand.NewPCG(0xcafe, 0xcafe), aow.SurveyCatalog, aow.WithOpenClusters(2), aow.WithStellarAssociations(1))
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		if err := g.BackgroundPopulation(); err != nil {
+			t.Fatalf("BackgroundPopulation() error = %v", err)
+		}
+		return g
+	}
+	g := generate()
+
+	background := regexp.MustCompile(`^AOW \d{4}$`)
+	cluster := regexp.MustCompile(`^AOW C[12]-\d{4}$`)
+	association := regexp.MustCompile(`^AOW A1-\d{4}$`)
+	ids, designations := make(map[aow.SystemID_t]bool), make(map[string]bool)
+	for _, ss := range g.Catalog.StarSystems {
+		if ids[ss.ID] || designations[ss.Designation] {
+			t.Errorf("duplicate id %v or designation %q", ss.ID, ss.Designation)
+		}
+		ids[ss.ID], designations[ss.Designation] = true, true
+		switch {
+		case ss.InCluster:
+			if !cluster.MatchString(ss.Designation) {
+				t.Errorf("cluster member designation %q", ss.Designation)
+			}
+		case ss.InAssociation:
+			if !association.MatchString(ss.Designation) {
+				t.Errorf("association member designation %q", ss.Designation)
+			}
+		default:
+			if !background.MatchString(ss.Designation) {
+				t.Errorf("background designation %q", ss.Designation)
+			}
+		}
+	}
+	if first := g.Catalog.StarSystems[0]; first.Designation != "AOW 0001" {
+		t.Errorf("first designation = %q, want %q", first.Designation, "AOW 0001")
+	}
+
+	// the identifiers don't depend on the order of the catalog
+	expected := make(map[aow.SystemID_t]string)
+	for _, ss := range g.Catalog.StarSystems {
+		expected[ss.ID] = ss.Designation
+	}
+	g.Catalog.SortByDistance(aow.Coordinates{X: 3, Y: -2, Z: 1})
+	for _, ss := range g.Catalog.StarSystems {
+		if expected[ss.ID] != ss.Designation {
+			t.Errorf("SortByDistance() changed the designation of %v from %q to %q", ss.ID, expected[ss.ID], ss.Designation)
+		}
+		if g.Catalog.Lookup(ss.ID) != ss || g.Catalog.LookupDesignation(ss.Designation) != ss {
+			t.Errorf("Lookup(%v) or LookupDesignation(%q) didn't find the system", ss.ID, ss.Designation)
+		}
+	}
+	if g.Catalog.LookupDesignation("AOW 9999") != nil {
+		t.Errorf("LookupDesignation(%q) found a system", "AOW 9999")
+	}
+
+	// the same generator assigns the same identifiers
+	other := generate()
+	for _, ss := range other.Catalog.StarSystems {
+		if expected[ss.ID] != ss.Designation {
+			t.Errorf("second generator designated %v as %q, want %q", ss.ID, ss.Designation, expected[ss.ID])
+		}
+	}
+}
+
+func TestCatalog_MergeTwice(t *testing.T) {
+	g, err := aow.New(100, rand.NewPCG(0xcafe, 0xcafe), aow.SurveyCatalog, aow.WithOpenClusters(0), aow.WithStellarAssociations(0))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := g.BackgroundPopulation(); err != nil {
+		t.Fatalf("BackgroundPopulation() error = %v", err)
+	}
+	cluster, err := g.OpenCluster(aow.Coordinates{})
+	if err != nil {
+		t.Fatalf("OpenCluster() error = %v", err)
+	}
+	first := cluster.Catalog.StarSystems[0]
+	g.Catalog.MergeCluster(cluster)
+	g.Catalog.MergeCluster(cluster)
+
+	// the second copy of each member gets its own ID and designation
+	ids, designations := make(map[aow.SystemID_t]bool), make(map[string]bool)
+	for _, ss := range g.Catalog.StarSystems {
+		if ids[ss.ID] || designations[ss.Designation] {
+			t.Fatalf("MergeCluster() duplicate id %v or designation %q", ss.ID, ss.Designation)
+		}
+		ids[ss.ID], designations[ss.Designation] = true, true
+		if g.Catalog.Lookup(ss.ID) != ss || g.Catalog.LookupDesignation(ss.Designation) != ss {
+			t.Fatalf("Lookup(%v) or LookupDesignation(%q) didn't find the system", ss.ID, ss.Designation)
+		}
+	}
+	if second := g.Catalog.LookupDesignation(first.Designation + "/2"); second == nil || second.ID == first.ID {
+		t.Errorf("LookupDesignation(%q) = %v, want the second copy", first.Designation+"/2", second)
+	}
+
+	// replacing a system in place needs a Reindex
+	replacement := &aow.StarSystem_t{ID: 42, Designation: "AOW X"}
+	g.Catalog.StarSystems[0] = replacement
+	g.Catalog.Reindex()
+	if g.Catalog.Lookup(42) != replacement || g.Catalog.LookupDesignation("AOW X") != replacement {
+		t.Errorf("Lookup() after Reindex didn't find the replacement")
+	}
+}
+
+func TestDesignations_Unnumbered(t *testing.T) {
+	// clusters and associations created on their own are designated by their seeds
+	designations := make(map[string]int)
+	for seed := uint64(7); seed <= 8; seed++ {
+		cluster, err := aow.NewOpenCluster(aow.NewSeededPRNG(seed))
+		if err != nil {
+			t.Fatalf("NewOpenCluster() error = %v", err)
+		}
+		association, err := aow.NewStellarAssociation(aow.NewSeededPRNG(seed))
+		if err != nil {
+			t.Fatalf("NewStellarAssociation() error = %v", err)
+		}
+		for _, ss := range append(cluster.Catalog.StarSystems, association.Catalog.StarSystems...) {
+			if other, ok := designations[ss.Designation]; ok {
+				t.Fatalf("seeds %d and %d both designated %q", other, seed, ss.Designation)
+			}
+			designations[ss.Designation] = int(seed)
+		}
+		if first := association.Catalog.StarSystems[0].Designation; !regexp.MustCompile(`^AOW A#[0-9a-f]{8}-0001$`).MatchString(first) {
+			t.Errorf("NewStellarAssociation() designation %q, want a seed-based designation", first)
+		}
+	}
+}
+
+func TestSystemID_String(t *testing.T) {
+	if result := aow.SystemID_t(0x2a).String(); result != "000000000000002a" {
+		t.Errorf("String() = %q, want %q", result, "000000000000002a")
+	}
+}
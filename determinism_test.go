@@ -0,0 +1,50 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/mdhender/aow"
+	"math/rand/v2"
+	"testing"
+)
+
+// generateWithCluster runs the same steps as cmd/bob and returns the catalog as JSON.
+func generateWithCluster(t *testing.T) []byte {
+	g, err := aow.New(1_000, rand.NewPCG(0xcafe, 0xcafe), aow.SurveyCatalog)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := g.BackgroundPopulation(); err != nil {
+		t.Fatalf("BackgroundPopulation() error = %v", err)
+	}
+	clusterCenter := g.GenZonedXYZ(0.77, 0.89)
+	cluster, err := g.OpenCluster(clusterCenter)
+	if err != nil {
+		t.Fatalf("OpenCluster() error = %v", err)
+	}
+	g.Catalog.MergeCluster(cluster)
+	g.Catalog.SortByDistance(aow.Coordinates{})
+	data, err := json.Marshal(g.Catalog)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	return data
+}
+
+func TestGenerator_Deterministic(t *testing.T) {
+	a, b := generateWithCluster(t), generateWithCluster(t)
+	if !bytes.Equal(a, b) {
+		t.Errorf("generators with the same seed produced different catalogs")
+	}
+}
+
+func TestPRNG_GenZonedXYZ_Deterministic(t *testing.T) {
+	pa, pb := aow.NewPRNG(rand.NewPCG(0xcafe, 0xcafe)), aow.NewPRNG(rand.NewPCG(0xcafe, 0xcafe))
+	for n := 0; n < 1_000; n++ {
+		if a, b := pa.GenZonedXYZ(0.2, 1.0), pb.GenZonedXYZ(0.2, 1.0); a != b {
+			t.Fatalf("GenZonedXYZ() = %v and %v, want identical coordinates", a, b)
+		}
+	}
+}
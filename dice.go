@@ -0,0 +1,233 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Dice_t is a parsed dice expression.
+//
+// The notation is "NdS", optionally followed by "khK" or "klK" to keep the highest
+// or lowest K dice, then "*M" to multiply the total, then "+C" or "-C" to add a
+// constant. The count defaults to 1, and "d%" is the same as "d100". For example,
+// "3d6", "2d6-2", "d100", "4d6kh3" and "1d10*10".
+// The count, sides and multiplier can't be more than 1,000.
+type Dice_t struct {
+	Count       int  // number of dice rolled
+	Sides       int  // sides on each die
+	Keep        int  // number of dice kept; 0 keeps all of them
+	KeepHighest bool // keep the highest dice (otherwise the lowest)
+	Multiplier  int  // multiplies the sum of the dice kept
+	Modifier    int  // added after multiplying
+}
+
+// limits on the numbers in a dice expression, so that rolling the dice is cheap
+// and the result can't overflow
+const (
+	maxDiceCount      = 1_000
+	maxDiceSides      = 1_000
+	maxDiceMultiplier = 1_000
+	maxDiceModifier   = 1_000_000
+)
+
+// ParseDice parses a dice expression. The expression can be rolled any number of times.
+func ParseDice(expr string) (*Dice_t, error) {
+	d := &Dice_t{Count: 1, Multiplier: 1}
+	s := strings.ToLower(strings.TrimSpace(expr))
+
+	// optional count, then the "d"
+	count, s := leadingNumber(s)
+	if count != "" {
+		var err error
+		if d.Count, err = parseDiceNumber(expr, "count", count, maxDiceCount); err != nil {
+			return nil, err
+		}
+	}
+	if !strings.HasPrefix(s, "d") {
+		return nil, fmt.Errorf("%q: %w", expr, ErrInvalidDiceExpression)
+	}
+	s = s[1:]
+
+	// sides
+	if strings.HasPrefix(s, "%") {
+		d.Sides, s = 100, s[1:]
+	} else {
+		var sides string
+		if sides, s = leadingNumber(s); sides == "" {
+			return nil, fmt.Errorf("%q: missing sides: %w", expr, ErrInvalidDiceExpression)
+		}
+		var err error
+		if d.Sides, err = parseDiceNumber(expr, "sides", sides, maxDiceSides); err != nil {
+			return nil, err
+		}
+	}
+
+	// optional keep highest or lowest
+	if strings.HasPrefix(s, "kh") || strings.HasPrefix(s, "kl") {
+		d.KeepHighest = strings.HasPrefix(s, "kh")
+		var keep string
+		if keep, s = leadingNumber(s[2:]); keep == "" {
+			return nil, fmt.Errorf("%q: missing number of dice to keep: %w", expr, ErrInvalidDiceExpression)
+		}
+		var err error
+		if d.Keep, err = parseDiceNumber(expr, "keep", keep, maxDiceCount); err != nil {
+			return nil, err
+		} else if d.Keep < 1 || d.Keep > d.Count {
+			return nil, fmt.Errorf("%q: can't keep %d of %d dice: %w", expr, d.Keep, d.Count, ErrInvalidDiceExpression)
+		}
+	}
+
+	// optional multiplier
+	if strings.HasPrefix(s, "*") {
+		var multiplier string
+		if multiplier, s = leadingNumber(s[1:]); multiplier == "" {
+			return nil, fmt.Errorf("%q: missing multiplier: %w", expr, ErrInvalidDiceExpression)
+		}
+		var err error
+		if d.Multiplier, err = parseDiceNumber(expr, "multiplier", multiplier, maxDiceMultiplier); err != nil {
+			return nil, err
+		}
+	}
+
+	// optional modifier
+	if strings.HasPrefix(s, "+") || strings.HasPrefix(s, "-") {
+		sign := s[0]
+		var modifier string
+		if modifier, s = leadingNumber(s[1:]); modifier == "" {
+			return nil, fmt.Errorf("%q: missing modifier: %w", expr, ErrInvalidDiceExpression)
+		}
+		var err error
+		if d.Modifier, err = parseDiceNumber(expr, "modifier", modifier, maxDiceModifier); err != nil {
+			return nil, err
+		}
+		if sign == '-' {
+			d.Modifier = -d.Modifier
+		}
+	}
+
+	if s != "" {
+		return nil, fmt.Errorf("%q: unexpected %q: %w", expr, s, ErrInvalidDiceExpression)
+	} else if d.Count < 1 || d.Sides < 1 {
+		return nil, fmt.Errorf("%q: %w", expr, ErrInvalidDiceExpression)
+	}
+
+	return d, nil
+}
+
+// MustParseDice is like ParseDice but panics if the expression is invalid.
+// It is intended for expressions that are compiled into the program.
+func MustParseDice(expr string) *Dice_t {
+	d, err := ParseDice(expr)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// Roll rolls the dice and returns the result.
+func (d *Dice_t) Roll(p PRNG) int {
+	rolls := make([]int, d.Count)
+	for i := range rolls {
+		rolls[i] = p.IntN(d.Sides) + 1
+	}
+	if d.Keep > 0 {
+		if d.KeepHighest {
+			sort.Sort(sort.Reverse(sort.IntSlice(rolls)))
+		} else {
+			sort.Ints(rolls)
+		}
+		rolls = rolls[:d.Keep]
+	}
+	var sum int
+	for _, roll := range rolls {
+		sum += roll
+	}
+	return sum*d.Multiplier + d.Modifier
+}
+
+// Min returns the lowest possible result.
+func (d *Dice_t) Min() int {
+	return d.kept()*d.Multiplier + d.Modifier
+}
+
+// Max returns the highest possible result.
+func (d *Dice_t) Max() int {
+	return d.kept()*d.Sides*d.Multiplier + d.Modifier
+}
+
+// String implements the Stringer interface. The result can be parsed by ParseDice.
+func (d *Dice_t) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%dd%d", d.Count, d.Sides)
+	if d.Keep > 0 {
+		if d.KeepHighest {
+			fmt.Fprintf(&sb, "kh%d", d.Keep)
+		} else {
+			fmt.Fprintf(&sb, "kl%d", d.Keep)
+		}
+	}
+	if d.Multiplier != 1 {
+		fmt.Fprintf(&sb, "*%d", d.Multiplier)
+	}
+	if d.Modifier != 0 {
+		fmt.Fprintf(&sb, "%+d", d.Modifier)
+	}
+	return sb.String()
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (d *Dice_t) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (d *Dice_t) UnmarshalText(text []byte) error {
+	parsed, err := ParseDice(string(text))
+	if err != nil {
+		return err
+	}
+	*d = *parsed
+	return nil
+}
+
+// clone returns a copy of the dice, or nil if the dice are nil.
+func (d *Dice_t) clone() *Dice_t {
+	if d == nil {
+		return nil
+	}
+	nd := *d
+	return &nd
+}
+
+// kept returns the number of dice that count towards the result.
+func (d *Dice_t) kept() int {
+	if d.Keep > 0 {
+		return d.Keep
+	}
+	return d.Count
+}
+
+// parseDiceNumber returns the value of the digits from a part of the expression.
+// It returns an error if the value is larger than the limit for the part.
+func parseDiceNumber(expr, part, digits string, limit int) (int, error) {
+	n, err := strconv.Atoi(digits)
+	if err != nil {
+		return 0, fmt.Errorf("%q: %s: %w: %w", expr, part, err, ErrInvalidDiceExpression)
+	} else if n > limit {
+		return 0, fmt.Errorf("%q: %s %d is more than %d: %w", expr, part, n, limit, ErrInvalidDiceExpression)
+	}
+	return n, nil
+}
+
+// leadingNumber splits the leading digits from the string.
+func leadingNumber(s string) (string, string) {
+	n := 0
+	for n < len(s) && '0' <= s[n] && s[n] <= '9' {
+		n++
+	}
+	return s[:n], s[n:]
+}
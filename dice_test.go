@@ -0,0 +1,72 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow_test
+
+import (
+	"errors"
+	"github.com/mdhender/aow"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestParseDice(t *testing.T) {
+	for _, tc := range []struct {
+		expr     string
+		min, max int
+		str      string
+	}{
+		{"3d6", 3, 18, "3d6"},
+		{"2d6-2", 0, 10, "2d6-2"},
+		{"d100", 1, 100, "1d100"},
+		{"d%", 1, 100, "1d100"},
+		{"4d6kh3", 3, 18, "4d6kh3"},
+		{"4d6kl3", 3, 18, "4d6kl3"},
+		{"1d10*10", 10, 100, "1d10*10"},
+		{" 2D10*10+5 ", 25, 205, "2d10*10+5"},
+	} {
+		d, err := aow.ParseDice(tc.expr)
+		if err != nil {
+			t.Errorf("ParseDice(%q) error = %v", tc.expr, err)
+			continue
+		}
+		if d.Min() != tc.min || d.Max() != tc.max {
+			t.Errorf("ParseDice(%q) range = %d..%d, want %d..%d", tc.expr, d.Min(), d.Max(), tc.min, tc.max)
+		}
+		if d.String() != tc.str {
+			t.Errorf("ParseDice(%q) string = %q, want %q", tc.expr, d.String(), tc.str)
+		}
+		p := aow.NewPRNG(rand.NewPCG(0xcafe, 0xcafe)) // Use a fixed seed for reproducibility
+		seen := make(map[int]bool)
+		for n := 0; n < 10_000; n++ {
+			result := d.Roll(p)
+			if result < tc.min || result > tc.max {
+				t.Errorf("ParseDice(%q).Roll() = %d, want between %d and %d", tc.expr, result, tc.min, tc.max)
+			}
+			seen[result] = true
+		}
+		if !seen[tc.min] || !seen[tc.max] {
+			t.Errorf("ParseDice(%q).Roll() never rolled %d or %d", tc.expr, tc.min, tc.max)
+		}
+	}
+}
+
+func TestParseDice_KeepHighest(t *testing.T) {
+	p := aow.NewPRNG(rand.NewPCG(0xcafe, 0xcafe)) // Use a fixed seed for reproducibility
+	high, low := aow.MustParseDice("4d6kh3"), aow.MustParseDice("4d6kl3")
+	var highSum, lowSum int
+	for n := 0; n < 1_000; n++ {
+		highSum, lowSum = highSum+high.Roll(p), lowSum+low.Roll(p)
+	}
+	if highSum <= lowSum {
+		t.Errorf("4d6kh3 total %d, want more than 4d6kl3 total %d", highSum, lowSum)
+	}
+}
+
+func TestParseDice_Errors(t *testing.T) {
+	for _, expr := range []string{"", "3", "3d", "d", "0d6", "3d0", "3x6", "4d6kh5", "4d6kh", "1d10*", "2d6-", "2d6+2+2", "2d6 + 2",
+		"100000000000d6", "1001d6", "1d1001", "3d99999999999999999999", "1d6*1001", "1d6+1000001", "1d6-99999999999999999999"} {
+		if _, err := aow.ParseDice(expr); !errors.Is(err, aow.ErrInvalidDiceExpression) {
+			t.Errorf("ParseDice(%q) error = %v, want %v", expr, err, aow.ErrInvalidDiceExpression)
+		}
+	}
+}
@@ -13,4 +13,18 @@ const (
 	ErrNeighborhoodOffsetTooSmall = Error("galactic neighborhood offset too small")
 	ErrNeighborhoodOffsetTooLarge = Error("galactic neighborhood offset too large")
 	ErrPRNGNil                    = Error("PRNG cannot be nil")
+	ErrInterestPredicateNil       = Error("interest predicate cannot be nil")
+	ErrEarthLikeTargetNotMet      = Error("target number of Earth-like systems not met")
+	ErrInvalidDiceExpression      = Error("invalid dice expression")
+	ErrInvalidRollKind            = Error("invalid roll kind")
+	ErrRecorderNil                = Error("recorder cannot be nil")
+	ErrInvalidOverride            = Error("invalid override")
+	ErrInvalidTable               = Error("invalid table")
+	ErrTablesNil                  = Error("tables cannot be nil")
+	ErrNegativeCount              = Error("count cannot be negative")
+	ErrUnknownNameStyle           = Error("unknown name style")
+	ErrUnsupportedSchema          = Error("unsupported schema version")
+	ErrMissingColumn              = Error("missing column")
+	ErrMalformedRow               = Error("malformed row")
+	ErrInvalidCatalog             = Error("invalid catalog")
 )
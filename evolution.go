@@ -0,0 +1,234 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow
+
+import (
+	"fmt"
+	"math"
+)
+
+// solarTemperature is the effective temperature of Sol, in Kelvin.
+const solarTemperature = 5_772.0
+
+// StellarPhase_e is the phase in the life of a star.
+type StellarPhase_e int
+
+const (
+	MainSequencePhase StellarPhase_e = iota
+	SubgiantPhase
+	GiantPhase
+	WhiteDwarfPhase
+	BrownDwarfPhase
+	NeutronStarPhase
+	BlackHolePhase
+)
+
+// String implements the Stringer interface.
+func (p StellarPhase_e) String() string {
+	switch p {
+	case MainSequencePhase:
+		return "main sequence"
+	case SubgiantPhase:
+		return "subgiant"
+	case GiantPhase:
+		return "giant"
+	case WhiteDwarfPhase:
+		return "white dwarf"
+	case BrownDwarfPhase:
+		return "brown dwarf"
+	case NeutronStarPhase:
+		return "neutron star"
+	case BlackHolePhase:
+		return "black hole"
+	}
+	return fmt.Sprintf("StellarPhase_e(%d)", int(p))
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (p StellarPhase_e) MarshalText() ([]byte, error) {
+	switch p {
+	case MainSequencePhase, SubgiantPhase, GiantPhase, WhiteDwarfPhase, BrownDwarfPhase, NeutronStarPhase, BlackHolePhase:
+		return []byte(p.String()), nil
+	}
+	return nil, fmt.Errorf("stellar phase %d: %w", int(p), ErrInvalidCatalog)
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (p *StellarPhase_e) UnmarshalText(text []byte) error {
+	for _, value := range []StellarPhase_e{MainSequencePhase, SubgiantPhase, GiantPhase, WhiteDwarfPhase, BrownDwarfPhase, NeutronStarPhase, BlackHolePhase} {
+		if string(text) == value.String() {
+			*p = value
+			return nil
+		}
+	}
+	return fmt.Errorf("stellar phase %q: %w", string(text), ErrInvalidCatalog)
+}
+
+// StellarState_t is the current state of a star.
+type StellarState_t struct {
+	Phase         StellarPhase_e `json:"phase"`
+	Mass          float64        `json:"mass"`          // current mass, in solar masses
+	Luminosity    float64        `json:"luminosity"`    // in solar luminosities
+	Temperature   float64        `json:"temperature"`   // effective temperature, in Kelvin
+	Radius        float64        `json:"radius"`        // in solar radii
+	SpectralClass string         `json:"spectralClass"` // for example, "G2 V"; empty for neutron stars and black holes
+}
+
+// IsRemnant returns true if the star is a white dwarf, neutron star or black hole.
+func (s StellarState_t) IsRemnant() bool {
+	return s.Phase == WhiteDwarfPhase || s.Phase == NeutronStarPhase || s.Phase == BlackHolePhase
+}
+
+// StellarEvolution returns the current state of a star with the given initial mass and age.
+//
+// Parameters:
+//   - mass: The initial mass of the star, in solar masses.
+//   - age: The age of the star, in billions of years.
+//
+// Returns:
+//   - The phase, luminosity, temperature, radius and spectral classification of the star.
+func StellarEvolution(mass, age float64) StellarState_t {
+	if mass < brownDwarfMassLimit {
+		return brownDwarfState(mass, age)
+	}
+
+	lifespan := MainSequenceLifespan(mass)
+	msLuminosity, msTemperature := mainSequenceLuminosity(mass), mainSequenceTemperature(mass)
+
+	s := StellarState_t{Mass: mass}
+	switch {
+	case age < lifespan:
+		// stars brighten slowly as they age on the main sequence
+		s.Phase = MainSequencePhase
+		s.Luminosity = msLuminosity * (0.7 + 0.6*age/lifespan)
+		s.Temperature = msTemperature
+	case age < subgiantLifespan*lifespan:
+		// subgiants cool as they expand
+		f := (age - lifespan) / ((subgiantLifespan - 1) * lifespan)
+		s.Phase = SubgiantPhase
+		s.Luminosity = 1.3 * msLuminosity * (1 + 0.5*f)
+		s.Temperature = msTemperature
+		if msTemperature > 5_000 {
+			s.Temperature = msTemperature - f*(msTemperature-5_000)
+		}
+	case age < giantLifespan*lifespan:
+		f := (age - subgiantLifespan*lifespan) / ((giantLifespan - subgiantLifespan) * lifespan)
+		s.Phase = GiantPhase
+		s.Luminosity = math.Max(1.3*1.5*msLuminosity, 25*math.Pow(mass, 2.5))
+		s.Temperature = 5_000 - 1_500*f
+	default:
+		return RemnantState(mass, age-giantLifespan*lifespan)
+	}
+	s.Radius = radiusOf(s.Luminosity, s.Temperature)
+	s.SpectralClass = spectralClass(s.Temperature, s.Phase, mass)
+
+	return s
+}
+
+const (
+	// subgiantLifespan is the age (as a multiple of the main sequence lifespan) when the subgiant phase ends.
+	subgiantLifespan = 1.1
+	// giantLifespan is the age (as a multiple of the main sequence lifespan) when the giant phase ends.
+	giantLifespan = 1.2
+)
+
+// MainSequenceLifespan returns the time (in billions of years) a star with the given mass stays on the main sequence.
+func MainSequenceLifespan(mass float64) float64 {
+	return math.Max(10*math.Pow(mass, -2.5), 0.003)
+}
+
+// mainSequenceLuminosity returns the luminosity (in solar luminosities) of a star in the middle of its main sequence lifespan.
+func mainSequenceLuminosity(mass float64) float64 {
+	switch {
+	case mass < 0.43:
+		return 0.23 * math.Pow(mass, 2.3)
+	case mass < 2:
+		return math.Pow(mass, 4)
+	case mass < 55:
+		return 1.4 * math.Pow(mass, 3.5)
+	}
+	return 32_000 * mass
+}
+
+// mainSequenceTemperature returns the effective temperature (in Kelvin) of a main sequence star.
+func mainSequenceTemperature(mass float64) float64 {
+	switch {
+	case mass < 0.43:
+		return 3_770 * math.Pow(mass/0.43, 0.2)
+	case mass < 1:
+		return solarTemperature * math.Pow(mass, 0.505)
+	}
+	return solarTemperature * math.Pow(mass, 0.63)
+}
+
+// brownDwarfState returns the state of a brown dwarf. Brown dwarfs never fuse hydrogen, so they cool and dim as they age.
+func brownDwarfState(mass, age float64) StellarState_t {
+	s := StellarState_t{
+		Phase:      BrownDwarfPhase,
+		Mass:       mass,
+		Luminosity: 1e-4 * math.Pow(mass/0.05, 2.64) * math.Pow(math.Max(age, 0.01), -1.3),
+		Radius:     0.1,
+	}
+	s.Temperature = temperatureOf(s.Luminosity, s.Radius)
+	s.SpectralClass = spectralClass(s.Temperature, s.Phase, mass)
+	return s
+}
+
+// radiusOf returns the radius (in solar radii) of a body with the given luminosity (in solar luminosities) and temperature (in Kelvin).
+func radiusOf(luminosity, temperature float64) float64 {
+	return math.Sqrt(luminosity) * math.Pow(solarTemperature/temperature, 2)
+}
+
+// temperatureOf returns the effective temperature (in Kelvin) of a body with the given luminosity (in solar luminosities) and radius (in solar radii).
+func temperatureOf(luminosity, radius float64) float64 {
+	return solarTemperature * math.Pow(luminosity/(radius*radius), 0.25)
+}
+
+// spectralClass returns the spectral classification for the temperature and phase.
+// Stars get a Morgan-Keenan class (for example, "G2 V"), white dwarfs get a "D" class
+// and brown dwarfs get a spectral type with no luminosity class.
+func spectralClass(temperature float64, phase StellarPhase_e, initialMass float64) string {
+	if phase == WhiteDwarfPhase {
+		return fmt.Sprintf("DA%d", min(max(int(math.Round(50_400/temperature)), 1), 9))
+	}
+
+	class := spectralTypeTable[len(spectralTypeTable)-1]
+	for _, row := range spectralTypeTable {
+		if temperature >= row.minTemperature {
+			class = row
+			break
+		}
+	}
+	subclass := int(10 * (class.maxTemperature - temperature) / (class.maxTemperature - class.minTemperature))
+	subclass = min(max(subclass, 0), 9)
+
+	switch phase {
+	case MainSequencePhase:
+		return fmt.Sprintf("%s%d V", class.class, subclass)
+	case SubgiantPhase:
+		return fmt.Sprintf("%s%d IV", class.class, subclass)
+	case GiantPhase:
+		if initialMass >= 8 {
+			return fmt.Sprintf("%s%d I", class.class, subclass)
+		}
+		return fmt.Sprintf("%s%d III", class.class, subclass)
+	}
+	return fmt.Sprintf("%s%d", class.class, subclass)
+}
+
+// spectralTypeTable maps effective temperatures (in Kelvin) to spectral types, from hottest to coolest.
+var spectralTypeTable = []struct {
+	class                          string
+	minTemperature, maxTemperature float64
+}{
+	{class: "O", minTemperature: 30_000, maxTemperature: 50_000},
+	{class: "B", minTemperature: 10_000, maxTemperature: 30_000},
+	{class: "A", minTemperature: 7_500, maxTemperature: 10_000},
+	{class: "F", minTemperature: 6_000, maxTemperature: 7_500},
+	{class: "G", minTemperature: 5_200, maxTemperature: 6_000},
+	{class: "K", minTemperature: 3_700, maxTemperature: 5_200},
+	{class: "M", minTemperature: 2_400, maxTemperature: 3_700},
+	{class: "L", minTemperature: 1_300, maxTemperature: 2_400},
+	{class: "T", minTemperature: 550, maxTemperature: 1_300},
+	{class: "Y", minTemperature: 0, maxTemperature: 550},
+}
@@ -0,0 +1,46 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow_test
+
+import (
+	"github.com/mdhender/aow"
+	"math"
+	"testing"
+)
+
+func TestStellarEvolution(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		mass, age     float64
+		phase         aow.StellarPhase_e
+		spectralClass string
+	}{
+		{"sol", 1.0, 4.6, aow.MainSequencePhase, "G2 V"},
+		{"red dwarf", 0.2, 5.0, aow.MainSequencePhase, "M3 V"},
+		{"subgiant", 1.2, 6.5, aow.SubgiantPhase, "F9 IV"},
+		{"giant", 2.0, 2.0, aow.GiantPhase, "K4 III"},
+		{"white dwarf", 3.0, 5.0, aow.WhiteDwarfPhase, "DA9"},
+		{"brown dwarf", 0.05, 1.0, aow.BrownDwarfPhase, "L5"},
+	} {
+		result := aow.StellarEvolution(tc.mass, tc.age)
+		if result.Phase != tc.phase {
+			t.Errorf("%s: StellarEvolution(%f, %f) phase = %v, want %v", tc.name, tc.mass, tc.age, result.Phase, tc.phase)
+		}
+		if result.SpectralClass != tc.spectralClass {
+			t.Errorf("%s: StellarEvolution(%f, %f) spectral class = %q, want %q", tc.name, tc.mass, tc.age, result.SpectralClass, tc.spectralClass)
+		}
+		if result.Luminosity <= 0 || result.Temperature <= 0 || result.Radius <= 0 {
+			t.Errorf("%s: StellarEvolution(%f, %f) = %+v, want positive luminosity, temperature and radius", tc.name, tc.mass, tc.age, result)
+		}
+	}
+}
+
+func TestStellarEvolution_Sol(t *testing.T) {
+	result := aow.StellarEvolution(1.0, 4.6)
+	if math.Abs(result.Luminosity-1) > 0.05 {
+		t.Errorf("StellarEvolution(1, 4.6) luminosity = %f, want 1", result.Luminosity)
+	}
+	if math.Abs(result.Radius-1) > 0.05 {
+		t.Errorf("StellarEvolution(1, 4.6) radius = %f, want 1", result.Radius)
+	}
+}
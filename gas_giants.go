@@ -0,0 +1,237 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow
+
+import "fmt"
+
+// GasGiantArrangement_e is the arrangement of gas giants around a star.
+type GasGiantArrangement_e int
+
+const (
+	// NoGasGiants means that no gas giants formed. The inner planets are undisturbed
+	// and the outer system holds only small icy bodies.
+	NoGasGiants GasGiantArrangement_e = iota
+	// ConventionalGasGiants means that gas giants formed beyond the snow line and
+	// stayed there. The inner planets are undisturbed.
+	ConventionalGasGiants
+	// EccentricGasGiants means that a gas giant was scattered onto an eccentric orbit
+	// near the snow line. It clears the orbits it crosses and disrupts the inner planets.
+	EccentricGasGiants
+	// EpistellarGasGiants means that a gas giant migrated inward to the innermost orbit.
+	// It swept up most of the material inside the snow line on the way.
+	EpistellarGasGiants
+)
+
+// String implements the Stringer interface.
+func (a GasGiantArrangement_e) String() string {
+	switch a {
+	case NoGasGiants:
+		return "no gas giants"
+	case ConventionalGasGiants:
+		return "conventional"
+	case EccentricGasGiants:
+		return "eccentric"
+	case EpistellarGasGiants:
+		return "epistellar"
+	}
+	return fmt.Sprintf("GasGiantArrangement_e(%d)", int(a))
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (a GasGiantArrangement_e) MarshalText() ([]byte, error) {
+	switch a {
+	case NoGasGiants, ConventionalGasGiants, EccentricGasGiants, EpistellarGasGiants:
+		return []byte(a.String()), nil
+	}
+	return nil, fmt.Errorf("gas giant arrangement %d: %w", int(a), ErrInvalidCatalog)
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (a *GasGiantArrangement_e) UnmarshalText(text []byte) error {
+	for _, value := range []GasGiantArrangement_e{NoGasGiants, ConventionalGasGiants, EccentricGasGiants, EpistellarGasGiants} {
+		if string(text) == value.String() {
+			*a = value
+			return nil
+		}
+	}
+	return fmt.Errorf("gas giant arrangement %q: %w", string(text), ErrInvalidCatalog)
+}
+
+// OrbitContent_e is the type of body occupying a planetary orbit.
+type OrbitContent_e int
+
+const (
+	EmptyOrbit OrbitContent_e = iota
+	GasGiant
+	TerrestrialPlanet
+	PlanetoidBelt
+)
+
+// String implements the Stringer interface.
+func (c OrbitContent_e) String() string {
+	switch c {
+	case EmptyOrbit:
+		return "empty"
+	case GasGiant:
+		return "gas giant"
+	case TerrestrialPlanet:
+		return "terrestrial planet"
+	case PlanetoidBelt:
+		return "planetoid belt"
+	}
+	return fmt.Sprintf("OrbitContent_e(%d)", int(c))
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (c OrbitContent_e) MarshalText() ([]byte, error) {
+	switch c {
+	case EmptyOrbit, GasGiant, TerrestrialPlanet, PlanetoidBelt:
+		return []byte(c.String()), nil
+	}
+	return nil, fmt.Errorf("orbit content %d: %w", int(c), ErrInvalidCatalog)
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (c *OrbitContent_e) UnmarshalText(text []byte) error {
+	for _, value := range []OrbitContent_e{EmptyOrbit, GasGiant, TerrestrialPlanet, PlanetoidBelt} {
+		if string(text) == value.String() {
+			*c = value
+			return nil
+		}
+	}
+	return fmt.Errorf("orbit content %q: %w", string(text), ErrInvalidCatalog)
+}
+
+// GasGiantArrangement rolls for the arrangement of gas giants around the star.
+// Low mass stars are less likely to form gas giants.
+func GasGiantArrangement(star Star_t, prng PRNG) GasGiantArrangement_e {
+	var modifier float64
+	switch {
+	case star.BrownDwarf:
+		modifier = -4
+	case star.Mass < 0.5:
+		modifier = -2
+	case star.Mass >= 1.5:
+		modifier = 1
+	}
+	switch roll := prng.WithLabel("gas giant arrangement").RollD6(3) + modifier; {
+	case roll <= 10:
+		return NoGasGiants
+	case roll <= 14:
+		return ConventionalGasGiants
+	case roll <= 16:
+		return EccentricGasGiants
+	}
+	return EpistellarGasGiants
+}
+
+// PlaceGasGiants rolls for the gas giant arrangement of the star and then fills
+// each of the star's orbits with a gas giant, terrestrial planet, planetoid belt,
+// or leaves it empty. The orbits must already have been placed.
+func PlaceGasGiants(star *Star_t, prng PRNG) {
+	star.Arrangement = GasGiantArrangement(*star, prng)
+	prng = prng.WithLabel("orbit content")
+
+	// find the first orbit beyond the snow line
+	snowLine := len(star.Orbits)
+	for n, orbit := range star.Orbits {
+		if orbit.Radius >= star.Disc.SnowLine {
+			snowLine = n
+			break
+		}
+	}
+
+	// gas giants need an orbit to form in
+	if star.Arrangement == ConventionalGasGiants && snowLine == len(star.Orbits) {
+		star.Arrangement = NoGasGiants
+	} else if star.Arrangement != NoGasGiants && len(star.Orbits) == 0 {
+		star.Arrangement = NoGasGiants
+	}
+
+	switch star.Arrangement {
+	case NoGasGiants:
+		for n := range star.Orbits {
+			star.Orbits[n].Content = smallBody(n >= snowLine, 0, prng)
+		}
+	case ConventionalGasGiants:
+		// the first gas giant forms just beyond the snow line and starves the orbit inside it
+		for n := range star.Orbits {
+			switch {
+			case n == snowLine:
+				star.Orbits[n].Content = GasGiant
+			case n == snowLine-1 && prng.RollD6(3) <= 12:
+				star.Orbits[n].Content = PlanetoidBelt
+			case n > snowLine:
+				star.Orbits[n].Content = outerBody(12, prng)
+			default:
+				star.Orbits[n].Content = smallBody(false, 0, prng)
+			}
+		}
+	case EccentricGasGiants:
+		// the gas giant was scattered from the last orbit inside the snow line (or the first beyond it)
+		giant := max(snowLine-1, 0)
+		star.Orbits[giant].Content = GasGiant
+		star.Orbits[giant].Eccentricity = 0.1 + 0.1*prng.RollD6(1)
+		periapsis := star.Orbits[giant].Radius * (1 - star.Orbits[giant].Eccentricity)
+		apoapsis := star.Orbits[giant].Radius * (1 + star.Orbits[giant].Eccentricity)
+		for n := range star.Orbits {
+			switch {
+			case n == giant:
+				continue
+			case periapsis/1.5 <= star.Orbits[n].Radius && star.Orbits[n].Radius <= apoapsis*1.5:
+				// the giant clears any orbit it crosses
+				star.Orbits[n].Content = EmptyOrbit
+			case n > giant:
+				star.Orbits[n].Content = outerBody(9, prng)
+			default:
+				// the inner planets are disrupted
+				star.Orbits[n].Content = smallBody(false, 3, prng)
+			}
+		}
+	case EpistellarGasGiants:
+		// the gas giant migrated to the innermost orbit
+		for n := range star.Orbits {
+			switch {
+			case n == 0:
+				star.Orbits[n].Content = GasGiant
+			case n < snowLine:
+				// the migration swept up most of the material inside the snow line
+				star.Orbits[n].Content = smallBody(false, 5, prng)
+			default:
+				star.Orbits[n].Content = outerBody(9, prng)
+			}
+		}
+	}
+}
+
+// outerBody returns the content of an orbit beyond the snow line in a system with gas giants.
+// A gas giant forms if 3d6 rolls at or below the target number.
+func outerBody(target float64, prng PRNG) OrbitContent_e {
+	if prng.RollD6(3) <= target {
+		return GasGiant
+	}
+	return smallBody(true, 0, prng)
+}
+
+// smallBody returns the content of an orbit that won't hold a gas giant.
+// The modifier is added to the roll; positive modifiers favor empty orbits.
+func smallBody(beyondSnowLine bool, modifier float64, prng PRNG) OrbitContent_e {
+	roll := prng.RollD6(3) + modifier
+	if beyondSnowLine {
+		// the outer system holds icy dwarfs and belts of icy planetoids
+		switch {
+		case roll <= 9:
+			return PlanetoidBelt
+		case roll <= 14:
+			return TerrestrialPlanet
+		}
+		return EmptyOrbit
+	}
+	switch {
+	case roll <= 13:
+		return TerrestrialPlanet
+	case roll <= 16:
+		return PlanetoidBelt
+	}
+	return EmptyOrbit
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow_test
+
+import (
+	"github.com/mdhender/aow"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestPlaceGasGiants(t *testing.T) {
+	p := aow.NewPRNG(rand.NewPCG(0xcafe, 0xcafe)) // Use a fixed seed for reproducibility
+	arrangements := make(map[aow.GasGiantArrangement_e]int)
+	for n := 0; n < 2_000; n++ {
+		stars := []aow.Star_t{{Mass: 1.0}}
+		aow.PlacePlanetaryOrbits(stars, p)
+		star := &stars[0]
+		aow.PlaceGasGiants(star, p)
+		arrangements[star.Arrangement]++
+
+		var giants int
+		for _, orbit := range star.Orbits {
+			if orbit.Content == aow.GasGiant {
+				giants++
+			}
+		}
+		switch star.Arrangement {
+		case aow.NoGasGiants:
+			if giants != 0 {
+				t.Errorf("PlaceGasGiants() %v has %d gas giants", star.Arrangement, giants)
+			}
+		case aow.ConventionalGasGiants:
+			for _, orbit := range star.Orbits {
+				if orbit.Content == aow.GasGiant && orbit.Radius < star.Disc.SnowLine {
+					t.Errorf("PlaceGasGiants() %v has gas giant at %f inside snow line %f", star.Arrangement, orbit.Radius, star.Disc.SnowLine)
+				}
+			}
+		case aow.EccentricGasGiants:
+			var eccentric bool
+			for _, orbit := range star.Orbits {
+				eccentric = eccentric || (orbit.Content == aow.GasGiant && orbit.Eccentricity > 0)
+			}
+			if !eccentric {
+				t.Errorf("PlaceGasGiants() %v has no eccentric gas giant", star.Arrangement)
+			}
+		case aow.EpistellarGasGiants:
+			if star.Orbits[0].Content != aow.GasGiant {
+				t.Errorf("PlaceGasGiants() %v innermost orbit is %v", star.Arrangement, star.Orbits[0].Content)
+			}
+		}
+	}
+	for _, arrangement := range []aow.GasGiantArrangement_e{aow.NoGasGiants, aow.ConventionalGasGiants, aow.EccentricGasGiants, aow.EpistellarGasGiants} {
+		if arrangements[arrangement] == 0 {
+			t.Errorf("PlaceGasGiants() never produced %v", arrangement)
+		}
+	}
+}
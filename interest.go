@@ -0,0 +1,68 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow
+
+// brightStarLuminosity is the luminosity (in solar luminosities) at which a star is considered bright.
+const brightStarLuminosity = 10.0
+
+// InterestPredicate returns true if a star system is "interesting" enough to be kept in a reference catalog.
+type InterestPredicate func(ss *StarSystem_t) bool
+
+// DefaultInterest is the predicate used for reference catalogs when the caller doesn't provide one.
+// It keeps systems with an Earth-like planet, a bright star, or membership in a cluster
+// or stellar association.
+var DefaultInterest = AnyOf(HasEarthLikePlanet, IsBrightStar, IsClusterMember, IsAssociationMember)
+
+// AnyOf returns a predicate that is true if any of the given predicates are true.
+func AnyOf(predicates ...InterestPredicate) InterestPredicate {
+	return func(ss *StarSystem_t) bool {
+		for _, predicate := range predicates {
+			if predicate(ss) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// AllOf returns a predicate that is true if all the given predicates are true.
+func AllOf(predicates ...InterestPredicate) InterestPredicate {
+	return func(ss *StarSystem_t) bool {
+		for _, predicate := range predicates {
+			if !predicate(ss) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// HasEarthLikePlanet is true if the system has an Earth-like planet.
+func HasEarthLikePlanet(ss *StarSystem_t) bool {
+	return ss.HasEarthLikePlanet()
+}
+
+// IsBrightStar is true if any star in the system is at least ten times as luminous as Sol.
+func IsBrightStar(ss *StarSystem_t) bool {
+	for _, star := range ss.Stars {
+		if star.State.Luminosity >= brightStarLuminosity {
+			return true
+		}
+	}
+	return false
+}
+
+// IsClusterMember is true if the system is a member of an open cluster.
+func IsClusterMember(ss *StarSystem_t) bool {
+	return ss.InCluster
+}
+
+// IsAssociationMember is true if the system is a member of a stellar association.
+func IsAssociationMember(ss *StarSystem_t) bool {
+	return ss.InAssociation
+}
+
+// IsNotRemnant is true if at least one star in the system is not a stellar remnant.
+func IsNotRemnant(ss *StarSystem_t) bool {
+	return !ss.IsRemnant()
+}
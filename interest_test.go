@@ -0,0 +1,82 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow_test
+
+import (
+	"github.com/mdhender/aow"
+	"io"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestGenerator_ReferenceCatalog(t *testing.T) {
+	survey, err := aow.New(1_000, rand.NewPCG(0xcafe, 0xcafe), aow.SurveyCatalog)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := survey.BackgroundPopulation(); err != nil {
+		t.Fatalf("BackgroundPopulation() error = %v", err)
+	}
+	if survey.Catalog.Kind != aow.SurveyCatalog {
+		t.Errorf("BackgroundPopulation() kind = %v, want %v", survey.Catalog.Kind, aow.SurveyCatalog)
+	}
+
+	reference, err := aow.New(1_000, rand.NewPCG(0xcafe, 0xcafe), aow.ReferenceCatalog)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := reference.BackgroundPopulation(); err != nil {
+		t.Fatalf("BackgroundPopulation() error = %v", err)
+	}
+	if reference.Catalog.Kind != aow.ReferenceCatalog {
+		t.Errorf("BackgroundPopulation() kind = %v, want %v", reference.Catalog.Kind, aow.ReferenceCatalog)
+	}
+
+	// the reference catalog keeps exactly the interesting systems from the survey
+	var interesting int
+	for _, ss := range survey.Catalog.StarSystems {
+		if aow.DefaultInterest(ss) {
+			interesting++
+		}
+	}
+	if reference.Catalog.Length() != interesting {
+		t.Errorf("BackgroundPopulation() reference catalog has %d systems, want %d", reference.Catalog.Length(), interesting)
+	}
+	if reference.Catalog.Length() == 0 || reference.Catalog.Length() >= survey.Catalog.Length() {
+		t.Errorf("BackgroundPopulation() reference catalog has %d systems, survey has %d", reference.Catalog.Length(), survey.Catalog.Length())
+	}
+}
+
+func TestGenerator_WithInterest(t *testing.T) {
+	g, err := aow.New(1_000, rand.NewPCG(0xcafe, 0xcafe), aow.ReferenceCatalog, aow.WithInterest(aow.HasEarthLikePlanet))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := g.BackgroundPopulation(); err != nil {
+		t.Fatalf("BackgroundPopulation() error = %v", err)
+	}
+	for _, ss := range g.Catalog.StarSystems {
+		if !ss.HasEarthLikePlanet() {
+			t.Errorf("BackgroundPopulation() kept a system without an Earth-like planet")
+		}
+	}
+
+	if _, err := aow.New(1_000, rand.NewPCG(0xcafe, 0xcafe), aow.ReferenceCatalog, aow.WithInterest(nil)); err != aow.ErrInterestPredicateNil {
+		t.Errorf("New() error = %v, want %v", err, aow.ErrInterestPredicateNil)
+	}
+}
+
+func TestStarSystem_NoStars(t *testing.T) {
+	// a system that hasn't been generated has no stars
+	ss := &aow.StarSystem_t{Designation: "AOW 0001"}
+	if ss.Primary() != nil {
+		t.Errorf("Primary() = %v, want nil", ss.Primary())
+	}
+	if ss.IsMultiple() || ss.IsRemnant() || ss.HasEarthLikePlanet() || aow.DefaultInterest(ss) {
+		t.Errorf("a system without stars is multiple, a remnant, Earth-like or interesting")
+	}
+	catalog := &aow.Catalog_t{StarSystems: []*aow.StarSystem_t{ss}}
+	if err := catalog.WriteCSV(io.Discard); err != nil {
+		t.Errorf("WriteCSV() error = %v", err)
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// the word lists that train the name generator; each file is a style
+//
+//go:embed names/*.txt
+var namesFS embed.FS
+
+// limits on the names generated
+const (
+	nameOrder       = 2  // the number of letters of context in the Markov chain
+	minNameLength   = 4  // in letters
+	maxNameLength   = 10 // in letters
+	maxNameAttempts = 100
+)
+
+// markers for the start and end of a word in the Markov chain
+const (
+	nameStart = '^'
+	nameEnd   = '$'
+)
+
+// NameStyles returns the styles of names the generator knows, sorted by name.
+func NameStyles() []string {
+	entries, err := namesFS.ReadDir("names")
+	if err != nil {
+		panic(err)
+	}
+	var styles []string
+	for _, entry := range entries {
+		styles = append(styles, strings.TrimSuffix(entry.Name(), ".txt"))
+	}
+	sort.Strings(styles)
+	return styles
+}
+
+// NameGenerator_t generates names with a Markov chain trained on the word list for a style.
+// It never returns the same name twice, and never returns a word from the list.
+type NameGenerator_t struct {
+	style    string
+	chain    map[string][]nameTransition_t // the letters that can follow each context
+	totals   map[string]int                // the number of transitions from each context
+	words    map[string]bool               // the words the chain was trained on
+	used     map[string]bool               // the names returned so far
+	suffixes map[string]int                // the last number given to each name that ran out
+	compound bool                          // set once the chain stops finding new single words
+}
+
+// nameTransition_t is a letter that can follow a context and the number of times it did.
+type nameTransition_t struct {
+	next  rune
+	count int
+}
+
+// NewNameGenerator returns a name generator for the style. See NameStyles.
+func NewNameGenerator(style string) (*NameGenerator_t, error) {
+	data, err := namesFS.ReadFile(path.Join("names", style+".txt"))
+	if err != nil {
+		return nil, fmt.Errorf("%q: %w", style, ErrUnknownNameStyle)
+	}
+	ng := &NameGenerator_t{
+		style:    style,
+		chain:    make(map[string][]nameTransition_t),
+		totals:   make(map[string]int),
+		words:    make(map[string]bool),
+		used:     make(map[string]bool),
+		suffixes: make(map[string]int),
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if word := strings.ToLower(strings.TrimSpace(scanner.Text())); word != "" {
+			ng.train(word)
+		}
+	}
+	return ng, nil
+}
+
+// Style returns the style of the names generated.
+func (ng *NameGenerator_t) Style() string {
+	return ng.style
+}
+
+// Name returns a new name. The name depends only on the draws from the PRNG and
+// the names already returned by the generator.
+//
+// Names are single words until the chain stops finding new ones, then two words,
+// like "Alkal Zubetel". The chains have room for about 1,000 single words in the
+// japanese and norse styles, 2,500 in classical and 5,000 in arabic, and for millions
+// of two-word names in every style, so numbered names like "Alkal Zubetel 2" only
+// show up in catalogs far larger than a map.
+func (ng *NameGenerator_t) Name(prng PRNG) string {
+	prng = prng.WithLabel("system name")
+	for attempt := 0; !ng.compound && attempt < maxNameAttempts; attempt++ {
+		if name := ng.word(prng); name != "" && !ng.used[name] {
+			return ng.use(name)
+		}
+	}
+	ng.compound = true
+	var name string
+	for attempt := 0; attempt < maxNameAttempts; attempt++ {
+		first, second := ng.word(prng), ng.word(prng)
+		if first == "" || second == "" || first == second {
+			continue
+		}
+		if name = first + " " + second; !ng.used[name] {
+			return ng.use(name)
+		}
+	}
+	if name == "" {
+		name = ng.generate(prng)
+	}
+	// number the last name, starting after the last number it was given
+	for n := max(ng.suffixes[name], 1) + 1; ; n++ {
+		if numbered := fmt.Sprintf("%s %d", name, n); !ng.used[numbered] {
+			ng.suffixes[name] = n
+			return ng.use(numbered)
+		}
+	}
+}
+
+// word returns a word from the chain, or an empty string if the word is too
+// short, too long, or in the word list.
+func (ng *NameGenerator_t) word(prng PRNG) string {
+	word := ng.generate(prng)
+	if n := len([]rune(word)); n < minNameLength || maxNameLength < n || ng.words[word] {
+		return ""
+	}
+	return word
+}
+
+// train adds the transitions in the word to the chain.
+func (ng *NameGenerator_t) train(word string) {
+	ng.words[word] = true
+	context := []rune(strings.Repeat(string(nameStart), nameOrder))
+	for _, next := range append([]rune(word), nameEnd) {
+		key := string(context)
+		transitions := ng.chain[key]
+		found := false
+		for i := range transitions {
+			if transitions[i].next == next {
+				transitions[i].count, found = transitions[i].count+1, true
+				break
+			}
+		}
+		if !found {
+			// keep the transitions sorted so that the draws don't depend on the order of the word list
+			transitions = append(transitions, nameTransition_t{next: next, count: 1})
+			sort.Slice(transitions, func(i, j int) bool { return transitions[i].next < transitions[j].next })
+		}
+		ng.chain[key], ng.totals[key] = transitions, ng.totals[key]+1
+		context = append(context[1:], next)
+	}
+}
+
+// generate walks the chain from the start of a word to the end.
+// It gives up and returns what it has if the word gets too long.
+func (ng *NameGenerator_t) generate(prng PRNG) string {
+	var name []rune
+	context := []rune(strings.Repeat(string(nameStart), nameOrder))
+	for len(name) <= maxNameLength {
+		key := string(context)
+		roll := prng.IntN(ng.totals[key])
+		var next rune
+		for _, transition := range ng.chain[key] {
+			if roll < transition.count {
+				next = transition.next
+				break
+			}
+			roll -= transition.count
+		}
+		if next == nameEnd {
+			break
+		}
+		name = append(name, next)
+		context = append(context[1:], next)
+	}
+	return string(name)
+}
+
+// use records the name and returns it with the first letter of each word capitalized.
+func (ng *NameGenerator_t) use(name string) string {
+	ng.used[name] = true
+	runes := []rune(name)
+	for n := range runes {
+		if n == 0 || runes[n-1] == ' ' {
+			runes[n] = unicode.ToUpper(runes[n])
+		}
+	}
+	return string(runes)
+}
+
+// NameSystems gives every star system in the catalog a name in the style.
+//
+// Each system draws its candidate names from its own seed, and the systems are named
+// in order of their IDs, so the names don't depend on the order of the catalog. A system
+// only gets a different name if an earlier system took the one it would have had.
+func (c *Catalog_t) NameSystems(style string) error {
+	ng, err := NewNameGenerator(style)
+	if err != nil {
+		return err
+	}
+	systems := append([]*StarSystem_t(nil), c.StarSystems...)
+	sort.SliceStable(systems, func(i, j int) bool {
+		if systems[i].ID != systems[j].ID {
+			return systems[i].ID < systems[j].ID
+		}
+		return systems[i].Seed < systems[j].Seed
+	})
+	for _, ss := range systems {
+		ss.Name = ng.Name(NewSeededPRNG(DeriveSeed(ss.Seed, seedKeyName)))
+	}
+	return nil
+}
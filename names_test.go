@@ -0,0 +1,115 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow_test
+
+import (
+	"errors"
+	"github.com/mdhender/aow"
+	"math/rand/v2"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func TestNameGenerator_Styles(t *testing.T) {
+	styles := aow.NameStyles()
+	if len(styles) == 0 {
+		t.Fatalf("NameStyles() returned no styles")
+	}
+	for _, style := range styles {
+		ng, err := aow.NewNameGenerator(style)
+		if err != nil {
+			t.Fatalf("%s: NewNameGenerator() error = %v", style, err)
+		}
+		prng := aow.NewSeededPRNG(0xcafe)
+		seen := make(map[string]bool)
+		for n := 0; n < 500; n++ {
+			name := ng.Name(prng)
+			if name == "" || !unicode.IsUpper([]rune(name)[0]) {
+				t.Errorf("%s: Name() = %q, want a capitalized name", style, name)
+			}
+			if seen[name] {
+				t.Errorf("%s: Name() = %q, duplicate name", style, name)
+			}
+			seen[name] = true
+		}
+	}
+
+	if _, err := aow.NewNameGenerator("klingon"); !errors.Is(err, aow.ErrUnknownNameStyle) {
+		t.Errorf("NewNameGenerator() error = %v, want %v", err, aow.ErrUnknownNameStyle)
+	}
+	if _, err := aow.New(100, rand.NewPCG(0xcafe, 0xcafe), aow.SurveyCatalog, aow.WithNameStyle("klingon")); !errors.Is(err, aow.ErrUnknownNameStyle) {
+		t.Errorf("New() error = %v, want %v", err, aow.ErrUnknownNameStyle)
+	}
+}
+
+func TestCatalog_NameSystems(t *testing.T) {
+	generate := func() *aow.Catalog_t {
+		g, err := aow.New(300, rand.NewPCG(0xcafe, 0xcafe), aow.SurveyCatalog, aow.WithOpenClusters(1), aow.WithNameStyle("classical"))
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		if err := g.BackgroundPopulation(); err != nil {
+			t.Fatalf("BackgroundPopulation() error = %v", err)
+		}
+		return g.Catalog
+	}
+	catalog := generate()
+
+	names := make(map[aow.SystemID_t]string)
+	seen := make(map[string]bool)
+	for _, ss := range catalog.StarSystems {
+		if ss.Name == "" {
+			t.Fatalf("NameSystems() left %s unnamed", ss.Designation)
+		} else if seen[ss.Name] {
+			t.Errorf("NameSystems() name %q is not unique", ss.Name)
+		}
+		seen[ss.Name], names[ss.ID] = true, ss.Name
+	}
+
+	// the names are reproducible from the seed
+	for _, ss := range generate().StarSystems {
+		if names[ss.ID] != ss.Name {
+			t.Fatalf("NameSystems() %s named %q, then %q", ss.Designation, names[ss.ID], ss.Name)
+		}
+	}
+
+	// and don't depend on the order of the catalog
+	catalog.SortByDistance(aow.Coordinates{X: 3, Y: -2, Z: 1})
+	if err := catalog.NameSystems("classical"); err != nil {
+		t.Fatalf("NameSystems() error = %v", err)
+	}
+	for _, ss := range catalog.StarSystems {
+		if names[ss.ID] != ss.Name {
+			t.Fatalf("NameSystems() after sorting %s named %q, want %q", ss.Designation, ss.Name, names[ss.ID])
+		}
+	}
+}
+
+func TestNameGenerator_Capacity(t *testing.T) {
+	// the single words each style has room for, give or take, before it needs two-word names
+	for style, singles := range map[string]int{"arabic": 3_000, "classical": 1_500, "japanese": 600, "norse": 600} {
+		ng, err := aow.NewNameGenerator(style)
+		if err != nil {
+			t.Fatalf("%s: NewNameGenerator() error = %v", style, err)
+		}
+		seen := make(map[string]bool)
+		first := -1
+		for n := 0; n < 20_000; n++ {
+			name := ng.Name(aow.NewSeededPRNG(aow.DeriveSeed(0xcafe, uint64(n))))
+			if seen[name] {
+				t.Fatalf("%s: Name() = %q, duplicate name", style, name)
+			}
+			seen[name] = true
+			switch words := len(strings.Fields(name)); {
+			case words == 2 && first < 0:
+				first = n
+			case words > 2:
+				t.Fatalf("%s: Name() = %q after %d names, want no numbered names", style, name, n)
+			}
+		}
+		if first < singles {
+			t.Errorf("%s: Name() ran out of single words after %d names, want at least %d", style, first, singles)
+		}
+	}
+}
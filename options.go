@@ -22,6 +22,96 @@ func WithOffset(r, h float64) Option {
 		} else if h > 1_250 {
 			return ErrNeighborhoodOffsetTooLarge
 		}
+		g.offset = &galacticOffset_t{r: r, h: h}
+		return nil
+	}
+}
+
+// WithInterest allows you to specify the predicate used to decide which star systems
+// are kept in a reference catalog. It has no effect on survey catalogs.
+// If not given, the generator uses DefaultInterest.
+func WithInterest(keep InterestPredicate) Option {
+	return func(g *Generator) error {
+		if keep == nil {
+			return ErrInterestPredicateNil
+		}
+		g.interest = keep
+		return nil
+	}
+}
+
+// WithEarthLikeSystems tells the generator to treat n as the target number of systems
+// with Earth-like planets rather than the total number of systems. The generator uses
+// the population model for Earth-like systems and keeps expanding the volume of the
+// catalog until the target is reached.
+func WithEarthLikeSystems() Option {
+	return func(g *Generator) error {
+		g.earthLike = true
+		return nil
+	}
+}
+
+// WithRecorder tells the generator to record every draw from its PRNGs in the recorder.
+// Use it to trace which table rolls produced a catalog.
+func WithRecorder(r *Recorder) Option {
+	return func(g *Generator) error {
+		if r == nil {
+			return ErrRecorderNil
+		}
+		g.prng = g.prng.WithRecorder(r)
+		return nil
+	}
+}
+
+// WithTables allows you to replace the tables from the book with house rules or errata.
+// The generator uses a copy of the tables. See LoadTables.
+func WithTables(t *Tables_t) Option {
+	return func(g *Generator) error {
+		if t == nil {
+			return ErrTablesNil
+		} else if err := t.Validate(); err != nil {
+			return err
+		}
+		g.tables = t.clone()
+		g.tables.BasicPopulationModel.CombinedDensity = g.tables.BasicPopulationModel.combinedDensity()
+		return nil
+	}
+}
+
+// WithOpenClusters forces the number of open clusters seeded in the map.
+// Use 0 to disable them. If not given, the generator decides based on the
+// volume of the map and its position in the galaxy.
+func WithOpenClusters(n int) Option {
+	return func(g *Generator) error {
+		if n < 0 {
+			return ErrNegativeCount
+		}
+		g.openClusters = &n
+		return nil
+	}
+}
+
+// WithStellarAssociations forces the number of stellar associations seeded in the map.
+// Use 0 to disable them. If not given, the generator decides based on the
+// volume of the map and its position in the galaxy.
+func WithStellarAssociations(n int) Option {
+	return func(g *Generator) error {
+		if n < 0 {
+			return ErrNegativeCount
+		}
+		g.stellarAssociations = &n
+		return nil
+	}
+}
+
+// WithNameStyle tells the generator to name every star system in the catalog
+// using the style. See NameStyles for the styles available.
+func WithNameStyle(style string) Option {
+	return func(g *Generator) error {
+		if _, err := NewNameGenerator(style); err != nil {
+			return err
+		}
+		g.nameStyle = style
 		return nil
 	}
 }
@@ -0,0 +1,105 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow
+
+import "math"
+
+// Disc_t is the protoplanetary disc that formed around a star.
+// All distances are in AU from the star.
+type Disc_t struct {
+	InnerLimit     float64  `json:"innerLimit"`     // planets can't form inside this limit
+	OuterLimit     float64  `json:"outerLimit"`     // planets can't form outside this limit
+	SnowLine       float64  `json:"snowLine"`       // volatiles condense into ices beyond this line
+	ForbiddenZones []Zone_t `json:"forbiddenZones"` // planetary orbits are unstable in these zones because of companions
+}
+
+// IsForbidden returns true if the distance is in any of the forbidden zones.
+func (d Disc_t) IsForbidden(radius float64) bool {
+	for _, zone := range d.ForbiddenZones {
+		if zone.Contains(radius) {
+			return true
+		}
+	}
+	return false
+}
+
+// Orbit_t is a planetary orbit around a star.
+type Orbit_t struct {
+	Radius       float64        `json:"radius"`       // average distance from the star, in AU
+	Eccentricity float64        `json:"eccentricity"` // eccentricity of the orbit
+	Content      OrbitContent_e `json:"content"`      // the body occupying the orbit
+	Planet       *Planet_t      `json:"planet"`       // terrestrial planet or planetoid belt; nil for other content
+}
+
+// NewDisc returns the protoplanetary disc for the star at the given index.
+// The disc is derived from the initial mass and luminosity of the star and is
+// truncated by the orbits of any companions.
+func NewDisc(stars []Star_t, n int) Disc_t {
+	star := stars[n]
+	luminosity := initialLuminosity(star.Mass)
+
+	d := Disc_t{
+		InnerLimit: math.Max(0.1*star.Mass, 0.01*math.Sqrt(luminosity)),
+		OuterLimit: 40 * star.Mass,
+		SnowLine:   4.85 * math.Sqrt(luminosity),
+	}
+
+	// a companion truncates the disc of the star it orbits, and the outer
+	// companion of a triple truncates the discs of the inner pair as well.
+	for i := max(n, 1); i < len(stars); i++ {
+		d.ForbiddenZones = append(d.ForbiddenZones, stars[i].Orbit.ForbiddenZone)
+	}
+
+	// the disc around a companion can't extend past its own forbidden zone
+	if n > 0 {
+		d.OuterLimit = math.Min(d.OuterLimit, star.Orbit.ForbiddenZone.Inner)
+	}
+
+	return d
+}
+
+// PlacePlanetaryOrbits creates the disc and places planetary orbits for every star in the system.
+//
+// Orbits are placed outward from the inner limit of the disc. The distance between
+// orbits is set by rolling on the orbital spacing table. Orbits that fall in a forbidden
+// zone are skipped.
+func PlacePlanetaryOrbits(stars []Star_t, prng PRNG) {
+	for n := range stars {
+		stars[n].Disc = NewDisc(stars, n)
+		stars[n].Orbits = nil
+		disc := stars[n].Disc
+		if disc.InnerLimit >= disc.OuterLimit {
+			continue
+		}
+		for radius := disc.InnerLimit * OrbitalSpacing(prng); radius <= disc.OuterLimit; radius *= OrbitalSpacing(prng) {
+			if disc.IsForbidden(radius) {
+				continue
+			}
+			stars[n].Orbits = append(stars[n].Orbits, Orbit_t{Radius: radius})
+		}
+	}
+}
+
+// OrbitalSpacing returns the ratio between the radius of an orbit and the next orbit inward.
+func OrbitalSpacing(prng PRNG) float64 {
+	switch roll := prng.WithLabel("orbital spacing").RollD6(3); {
+	case roll <= 4:
+		return 1.4
+	case roll <= 6:
+		return 1.5
+	case roll <= 8:
+		return 1.6
+	case roll <= 12:
+		return 1.7
+	case roll <= 14:
+		return 1.8
+	case roll <= 16:
+		return 1.9
+	}
+	return 2.0
+}
+
+// initialLuminosity returns the luminosity (in solar luminosities) of a star when it formed.
+func initialLuminosity(mass float64) float64 {
+	return 0.7 * mainSequenceLuminosity(mass)
+}
@@ -0,0 +1,50 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow_test
+
+import (
+	"github.com/mdhender/aow"
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestNewDisc_Sol(t *testing.T) {
+	disc := aow.NewDisc([]aow.Star_t{{Mass: 1.0}}, 0)
+	if math.Abs(disc.InnerLimit-0.1) > 0.001 {
+		t.Errorf("NewDisc() inner limit = %f, want 0.1", disc.InnerLimit)
+	}
+	if math.Abs(disc.OuterLimit-40) > 0.001 {
+		t.Errorf("NewDisc() outer limit = %f, want 40", disc.OuterLimit)
+	}
+	if math.Abs(disc.SnowLine-4.058) > 0.001 {
+		t.Errorf("NewDisc() snow line = %f, want 4.058", disc.SnowLine)
+	}
+	if len(disc.ForbiddenZones) != 0 {
+		t.Errorf("NewDisc() forbidden zones = %v, want none", disc.ForbiddenZones)
+	}
+}
+
+func TestPlacePlanetaryOrbits(t *testing.T) {
+	p := aow.NewPRNG(rand.NewPCG(0xcafe, 0xcafe)) // Use a fixed seed for reproducibility
+	for n := 0; n < 1_000; n++ {
+		stars := aow.NewStars(4.6, p)
+		aow.PlacePlanetaryOrbits(stars, p)
+		for i, star := range stars {
+			if i > 0 && star.Disc.OuterLimit > star.Orbit.ForbiddenZone.Inner {
+				t.Errorf("PlacePlanetaryOrbits() companion %d outer limit %f beyond forbidden zone %+v", i, star.Disc.OuterLimit, star.Orbit.ForbiddenZone)
+			}
+			for j, orbit := range star.Orbits {
+				if orbit.Radius < star.Disc.InnerLimit || orbit.Radius > star.Disc.OuterLimit {
+					t.Errorf("PlacePlanetaryOrbits() star %d orbit %f outside disc %+v", i, orbit.Radius, star.Disc)
+				}
+				if star.Disc.IsForbidden(orbit.Radius) {
+					t.Errorf("PlacePlanetaryOrbits() star %d orbit %f in forbidden zone", i, orbit.Radius)
+				}
+				if j > 0 && orbit.Radius < 1.4*star.Orbits[j-1].Radius {
+					t.Errorf("PlacePlanetaryOrbits() star %d orbit %f too close to %f", i, orbit.Radius, star.Orbits[j-1].Radius)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow
+
+import "math"
+
+// Planet_t is a terrestrial planet, or the largest body in a planetoid belt.
+// Physical characteristics are relative to Earth.
+type Planet_t struct {
+	Mass    float64 `json:"mass"`    // in Earth masses
+	Density float64 `json:"density"` // relative to Earth (5.51 g/cc)
+	Radius  float64 `json:"radius"`  // in Earth radii
+	Gravity float64 `json:"gravity"` // surface gravity, in g
+
+	EscapeVelocity         float64                 `json:"escapeVelocity"`         // in km/s
+	BlackbodyTemperature   float64                 `json:"blackbodyTemperature"`   // in Kelvin
+	MinimumMolecularWeight float64                 `json:"minimumMolecularWeight"` // the lightest gas the planet can retain
+	Atmosphere             AtmosphereComposition_e `json:"atmosphere"`             // composition class of the atmosphere
+	AtmosphericMass        float64                 `json:"atmosphericMass"`        // relative to Earth
+	Pressure               float64                 `json:"pressure"`               // surface pressure, in atmospheres
+	Hydrographics          float64                 `json:"hydrographics"`          // fraction of the surface covered by water or ice
+	SurfaceTemperature     float64                 `json:"surfaceTemperature"`     // average surface temperature, in Kelvin
+	Class                  WorldClass_e            `json:"class"`                  // summary of the surface conditions
+}
+
+// IsEarthLike returns true if the planet is an Earth-like world.
+func (p *Planet_t) IsEarthLike() bool {
+	return p != nil && p.Class == EarthLikeWorld
+}
+
+// PlaceTerrestrialPlanets generates the physical characteristics of every terrestrial
+// planet and planetoid belt orbiting the star. Gas giants must already have been placed.
+func PlaceTerrestrialPlanets(star *Star_t, prng PRNG) {
+	for n, orbit := range star.Orbits {
+		switch orbit.Content {
+		case TerrestrialPlanet, PlanetoidBelt:
+			star.Orbits[n].Planet = NewTerrestrialPlanet(*star, orbit, prng)
+		default:
+			star.Orbits[n].Planet = nil
+		}
+	}
+}
+
+// NewTerrestrialPlanet returns the physical characteristics of a terrestrial planet
+// (or the largest body in a planetoid belt) in the given orbit around the star.
+//
+// The mass comes from the terrestrial mass table. The density depends on the composition
+// of the planet: rock and iron inside the snow line, rock and ice beyond it. Larger planets
+// are compressed by their own gravity. The radius and surface gravity follow from the
+// mass and density.
+func NewTerrestrialPlanet(star Star_t, orbit Orbit_t, prng PRNG) *Planet_t {
+	beyondSnowLine := orbit.Radius >= star.Disc.SnowLine
+
+	prng = prng.WithLabel("terrestrial mass table")
+	var mass float64
+	if orbit.Content == PlanetoidBelt {
+		mass = 0.000_1 * math.Pow(100, prng.RollPercentile())
+	} else {
+		mass = TerrestrialMass(terrestrialMassModifier(star, beyondSnowLine), prng)
+	}
+
+	// uncompressed density of the material the planet formed from
+	prng = prng.WithLabel("planet density")
+	var density float64
+	if beyondSnowLine {
+		density = 0.30 + 0.15*prng.RollPercentile()
+	} else {
+		density = 0.70 + 0.15*prng.RollPercentile()
+		if orbit.Radius < 0.3*star.Disc.SnowLine {
+			// iron is concentrated close to the star
+			density += 0.10
+		}
+	}
+	// self-compression
+	density *= 1 + 0.25*math.Sqrt(mass)
+
+	p := &Planet_t{
+		Mass:    mass,
+		Density: density,
+		Radius:  math.Cbrt(mass / density),
+	}
+	p.Gravity = p.Density * p.Radius
+
+	return p
+}
+
+// TerrestrialMass rolls on the terrestrial mass table and returns a mass in Earth masses.
+// The modifier is added to the 3d6 roll; negative modifiers favor smaller planets.
+func TerrestrialMass(modifier int, prng PRNG) float64 {
+	roll := int(prng.RollD6(3)) + modifier
+	row := terrestrialMassTable[len(terrestrialMassTable)-1]
+	for _, r := range terrestrialMassTable {
+		if roll <= r.roll {
+			row = r
+			break
+		}
+	}
+	return row.minMass * math.Pow(row.maxMass/row.minMass, prng.RollPercentile())
+}
+
+// terrestrialMassModifier returns the modifier to the terrestrial mass roll.
+// Icy bodies beyond the snow line, planets in disrupted systems, and planets
+// around low mass stars tend to be smaller.
+func terrestrialMassModifier(star Star_t, beyondSnowLine bool) int {
+	var modifier int
+	if beyondSnowLine {
+		modifier -= 4
+	}
+	switch star.Arrangement {
+	case EccentricGasGiants, EpistellarGasGiants:
+		modifier -= 2
+	}
+	switch {
+	case star.BrownDwarf:
+		modifier -= 4
+	case star.Mass < 0.5:
+		modifier -= 2
+	}
+	return modifier
+}
+
+// terrestrialMassTable is the 3d6 table for terrestrial planet masses (in Earth masses).
+var terrestrialMassTable = []struct {
+	roll             int
+	minMass, maxMass float64
+}{
+	{roll: 5, minMass: 0.02, maxMass: 0.06},
+	{roll: 7, minMass: 0.06, maxMass: 0.15},
+	{roll: 9, minMass: 0.15, maxMass: 0.40},
+	{roll: 11, minMass: 0.40, maxMass: 0.80},
+	{roll: 13, minMass: 0.80, maxMass: 1.30},
+	{roll: 15, minMass: 1.30, maxMass: 2.50},
+	{roll: 18, minMass: 2.50, maxMass: 6.00},
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow_test
+
+import (
+	"github.com/mdhender/aow"
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestNewTerrestrialPlanet(t *testing.T) {
+	p := aow.NewPRNG(rand.NewPCG(0xcafe, 0xcafe)) // Use a fixed seed for reproducibility
+	star := aow.Star_t{Mass: 1.0, Disc: aow.NewDisc([]aow.Star_t{{Mass: 1.0}}, 0)}
+	for n := 0; n < 1_000; n++ {
+		for _, orbit := range []aow.Orbit_t{
+			{Radius: 1.0, Content: aow.TerrestrialPlanet},
+			{Radius: 10.0, Content: aow.TerrestrialPlanet},
+			{Radius: 2.7, Content: aow.PlanetoidBelt},
+		} {
+			planet := aow.NewTerrestrialPlanet(star, orbit, p)
+			if planet.Mass <= 0 || planet.Density <= 0 || planet.Radius <= 0 || planet.Gravity <= 0 {
+				t.Fatalf("NewTerrestrialPlanet(%+v) = %+v, want positive values", orbit, planet)
+			}
+			if orbit.Radius >= star.Disc.SnowLine && planet.Density > 0.7 {
+				t.Errorf("NewTerrestrialPlanet(%+v) density = %f, want icy density", orbit, planet.Density)
+			}
+			if orbit.Content == aow.PlanetoidBelt && planet.Mass > 0.01 {
+				t.Errorf("NewTerrestrialPlanet(%+v) mass = %f, want at most 0.01", orbit, planet.Mass)
+			}
+			if math.Abs(planet.Mass-planet.Density*math.Pow(planet.Radius, 3)) > 0.000_001 {
+				t.Errorf("NewTerrestrialPlanet(%+v) = %+v, mass does not match density and radius", orbit, planet)
+			}
+		}
+	}
+}
+
+func TestPlaceTerrestrialPlanets(t *testing.T) {
+	var planets, belts, forbidden int
+	for seed := uint64(1); seed <= 500; seed++ {
+		p := aow.NewSeededPRNG(seed)
+		stars := aow.NewStars(4.6, p)
+		aow.PlacePlanetaryOrbits(stars, p)
+		for n := range stars {
+			aow.PlaceGasGiants(&stars[n], p)
+			contents := make([]aow.OrbitContent_e, len(stars[n].Orbits))
+			for i, orbit := range stars[n].Orbits {
+				contents[i] = orbit.Content
+			}
+			forbidden += len(stars[n].Disc.ForbiddenZones)
+
+			aow.PlaceTerrestrialPlanets(&stars[n], p)
+			for i, orbit := range stars[n].Orbits {
+				if orbit.Content != contents[i] {
+					t.Fatalf("%d: PlaceTerrestrialPlanets() changed orbit %d from %v to %v", seed, i, contents[i], orbit.Content)
+				}
+				// only the orbits the gas giants left to the terrestrial planets get a planet
+				switch orbit.Content {
+				case aow.TerrestrialPlanet:
+					planets++
+					if orbit.Planet == nil || orbit.Planet.Mass < 0.02 || orbit.Planet.Mass > 6.0 {
+						t.Errorf("%d: PlaceTerrestrialPlanets() orbit %d planet %+v, want a mass from 0.02 to 6", seed, i, orbit.Planet)
+					}
+				case aow.PlanetoidBelt:
+					belts++
+					if orbit.Planet == nil || orbit.Planet.Mass < 0.000_1 || orbit.Planet.Mass > 0.01 {
+						t.Errorf("%d: PlaceTerrestrialPlanets() orbit %d belt %+v, want a mass from 0.0001 to 0.01", seed, i, orbit.Planet)
+					}
+				default:
+					if orbit.Planet != nil {
+						t.Errorf("%d: PlaceTerrestrialPlanets() put a planet in a %v orbit", seed, orbit.Content)
+					}
+				}
+				if orbit.Planet != nil && stars[n].Disc.IsForbidden(orbit.Radius) {
+					t.Errorf("%d: PlaceTerrestrialPlanets() planet at %f AU is in a forbidden zone %v", seed, orbit.Radius, stars[n].Disc.ForbiddenZones)
+				}
+			}
+		}
+	}
+	if planets == 0 || belts == 0 || forbidden == 0 {
+		t.Errorf("PlaceTerrestrialPlanets() placed %d planets and %d belts around stars with %d forbidden zones, want some of each", planets, belts, forbidden)
+	}
+}
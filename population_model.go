@@ -3,6 +3,7 @@
 package aow
 
 import (
+	"fmt"
 	"math"
 )
 
@@ -19,13 +20,19 @@ import (
 //   - The suggested population model to use for the systems. This includes the stellar population
 //     and the smallest volume of space that would likely contain the requested number of Earth-like planets.
 func PopulationModelForEarthLikeSystems(n int, tweak float64) PopulationModel_t {
-	pm := BasicPopulationModelTable()
+	return populationModelForEarthLikeSystems(BasicPopulationModelTable(), n, tweak)
+}
+
+// populationModelForEarthLikeSystems implements PopulationModelForEarthLikeSystems for the given basic model.
+func populationModelForEarthLikeSystems(pm PopulationModel_t, n int, tweak float64) PopulationModel_t {
 	cubicParsesPerSolLikeSystem := 150.0
 	if 0 < tweak && tweak <= 5 {
 		cubicParsesPerSolLikeSystem += tweak
 	}
 	// the formula from p24 of the book
 	pm.Volume = float64(n) * 2.0 * cubicParsesPerSolLikeSystem
+	// derive the radius from the volume
+	pm.Radius = math.Ceil(math.Cbrt((3 * pm.Volume) / (4 * math.Pi)))
 	return pm
 }
 
@@ -43,7 +50,11 @@ func PopulationModelForEarthLikeSystems(n int, tweak float64) PopulationModel_t
 //   - The suggested population model to use for the systems. This includes the stellar population
 //     and the smallest volume of space that would likely contain the requested number of systems.
 func PopulationModelForSolLikeNeighborhood(n int, tweak float64) PopulationModel_t {
-	pm := BasicPopulationModelTable()
+	return populationModelForSolLikeNeighborhood(BasicPopulationModelTable(), n, tweak)
+}
+
+// populationModelForSolLikeNeighborhood implements PopulationModelForSolLikeNeighborhood for the given basic model.
+func populationModelForSolLikeNeighborhood(pm PopulationModel_t, n int, tweak float64) PopulationModel_t {
 	cubicParsecsPerStarSystem := 12.0
 	if 0 < tweak && tweak <= 1 {
 		cubicParsecsPerStarSystem += tweak
@@ -85,33 +96,26 @@ func PopulationModelForOtherNeighborhoods(n int, r, h float64, tweak float64) Po
 }
 
 type PopulationModel_t struct {
-	Radius                  float64 // radius, in parsecs
-	Volume                  float64 // volume of the population in cubic parsecs
-	YoungPopulationI        populationModel_t
-	IntermediatePopulationI populationModel_t
-	OldPopulationI          populationModel_t
-	DiskPopulationII        populationModel_t
-	HaloPopulationII        populationModel_t
-	CombinedDensity         float64
+	Radius                  float64           `json:"radius"` // radius, in parsecs
+	Volume                  float64           `json:"volume"` // volume of the population in cubic parsecs
+	YoungPopulationI        populationModel_t `json:"youngPopulationI"`
+	IntermediatePopulationI populationModel_t `json:"intermediatePopulationI"`
+	OldPopulationI          populationModel_t `json:"oldPopulationI"`
+	DiskPopulationII        populationModel_t `json:"diskPopulationII"`
+	HaloPopulationII        populationModel_t `json:"haloPopulationII"`
+	CombinedDensity         float64           `json:"combinedDensity"`
 }
 
 type populationModel_t struct {
-	Density  float64 // star systems per cubic parsec
-	BaseAge  float64
-	AgeRange float64
+	Density  float64 `json:"density"` // star systems per cubic parsec
+	BaseAge  float64 `json:"baseAge"`
+	AgeRange float64 `json:"ageRange"`
 }
 
 // BasicPopulationModelTable returns a population model table for a region of space similar to Sol's neighborhood.
-// It uses the values from p25 of the book.
+// It uses the values from p25 of the book, which are shipped in tables/basic_population_model.json.
 func BasicPopulationModelTable() PopulationModel_t {
-	return PopulationModel_t{
-		YoungPopulationI:        populationModel_t{Density: 0.0344, BaseAge: 0.0, AgeRange: 2.0},
-		IntermediatePopulationI: populationModel_t{Density: 0.0272, BaseAge: 2.0, AgeRange: 3.0},
-		OldPopulationI:          populationModel_t{Density: 0.0158, BaseAge: 5.0, AgeRange: 3.0},
-		DiskPopulationII:        populationModel_t{Density: 0.00339, BaseAge: 8.0, AgeRange: 1.5},
-		HaloPopulationII:        populationModel_t{Density: 0.000339, BaseAge: 9.5, AgeRange: 3.0},
-		CombinedDensity:         0.081129,
-	}
+	return builtinTables().BasicPopulationModel
 }
 
 // AdvancedPopulationModelTable returns a population model table for a region of space that might differ from Sol's neighborhood.
@@ -139,11 +143,16 @@ func AdvancedPopulationModelTable(r, h float64) PopulationModel_t {
 	pm.HaloPopulationII.Density = 0.00339 * math.Pow(math.E, -(r/3_500)) * math.Pow(math.E, -(h/2_000))
 
 	// the combined density is saved for future calculations.
-	pm.CombinedDensity = pm.YoungPopulationI.Density + pm.IntermediatePopulationI.Density + pm.OldPopulationI.Density + pm.DiskPopulationII.Density + pm.HaloPopulationII.Density
+	pm.CombinedDensity = pm.combinedDensity()
 
 	return pm
 }
 
+// combinedDensity returns the sum of the densities of the stellar populations.
+func (pm PopulationModel_t) combinedDensity() float64 {
+	return pm.YoungPopulationI.Density + pm.IntermediatePopulationI.Density + pm.OldPopulationI.Density + pm.DiskPopulationII.Density + pm.HaloPopulationII.Density
+}
+
 // StellarPopulation_e is a grouping of stellar systems that have similar characteristics.
 type StellarPopulation_e int
 
@@ -154,3 +163,40 @@ const (
 	DiskPopulationII
 	HaloPopulationII
 )
+
+// String implements the Stringer interface.
+func (p StellarPopulation_e) String() string {
+	switch p {
+	case YoungPopulationI:
+		return "young population I"
+	case IntermediatePopulationI:
+		return "intermediate population I"
+	case OldPopulationI:
+		return "old population I"
+	case DiskPopulationII:
+		return "disk population II"
+	case HaloPopulationII:
+		return "halo population II"
+	}
+	return fmt.Sprintf("StellarPopulation_e(%d)", int(p))
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (p StellarPopulation_e) MarshalText() ([]byte, error) {
+	switch p {
+	case YoungPopulationI, IntermediatePopulationI, OldPopulationI, DiskPopulationII, HaloPopulationII:
+		return []byte(p.String()), nil
+	}
+	return nil, fmt.Errorf("stellar population %d: %w", int(p), ErrInvalidCatalog)
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (p *StellarPopulation_e) UnmarshalText(text []byte) error {
+	for _, value := range []StellarPopulation_e{YoungPopulationI, IntermediatePopulationI, OldPopulationI, DiskPopulationII, HaloPopulationII} {
+		if string(text) == value.String() {
+			*p = value
+			return nil
+		}
+	}
+	return fmt.Errorf("stellar population %q: %w", string(text), ErrInvalidCatalog)
+}
@@ -9,12 +9,77 @@ import (
 
 type PRNG struct {
 	*rand.Rand
+	recorder *Recorder     // nil unless draws are being recorded
+	label    string        // the rule that is drawing from the PRNG
+	replay   *ReplaySource // when set, derived PRNGs draw from the replay log
 }
 
 func NewPRNG(prng rand.Source) PRNG {
-	return PRNG{
+	p := PRNG{
 		Rand: rand.New(prng),
 	}
+	if replay, ok := prng.(*ReplaySource); ok {
+		p.replay = replay
+	}
+	return p
+}
+
+// WithRecorder returns a copy of the PRNG that records every draw in the recorder.
+// The copy shares its source with the original.
+func (p PRNG) WithRecorder(r *Recorder) PRNG {
+	p.recorder = r
+	return p
+}
+
+// WithLabel returns a copy of the PRNG that labels the draws it records.
+// The label should name the rule that consumes the draws, like "cluster age table".
+// It does nothing if the PRNG isn't recording.
+func (p PRNG) WithLabel(label string) PRNG {
+	if p.recorder != nil {
+		p.label = label
+	}
+	return p
+}
+
+// Recorder returns the recorder for the PRNG, or nil if the PRNG isn't recording.
+func (p PRNG) Recorder() *Recorder {
+	return p.recorder
+}
+
+// Uint64 returns a pseudo-random 64-bit value as a uint64.
+func (p PRNG) Uint64() uint64 {
+	v := p.Rand.Uint64()
+	if p.recorder != nil {
+		p.recorder.record(Roll_t{Label: p.label, Kind: Uint64Roll, Result: v})
+	}
+	return v
+}
+
+// IntN returns a pseudo-random number in the half-open interval [0,n).
+func (p PRNG) IntN(n int) int {
+	v := p.Rand.IntN(n)
+	if p.recorder != nil {
+		p.recorder.record(Roll_t{Label: p.label, Kind: IntNRoll, N: n, Result: uint64(v)})
+	}
+	return v
+}
+
+// Float64 returns a pseudo-random number in the half-open interval [0.0,1.0).
+func (p PRNG) Float64() float64 {
+	v := p.Rand.Float64()
+	if p.recorder != nil {
+		p.recorder.record(Roll_t{Label: p.label, Kind: Float64Roll, Float: v})
+	}
+	return v
+}
+
+// derive returns a PRNG initialized from the seed that records to the same recorder.
+// If the PRNG is replaying a log, the derived PRNG ignores the seed and continues the log.
+func (p PRNG) derive(seed uint64) PRNG {
+	if p.replay != nil {
+		return NewPRNG(p.replay).WithRecorder(p.recorder)
+	}
+	return NewSeededPRNG(seed).WithRecorder(p.recorder)
 }
 
 func (p PRNG) FlipCoin() bool {
@@ -51,6 +116,16 @@ func (p PRNG) RollD10(n int) float64 {
 	return float64(result)
 }
 
+// Roll parses the dice expression and rolls it.
+// If the expression will be rolled many times, use ParseDice once and roll the result.
+func (p PRNG) Roll(expr string) (int, error) {
+	d, err := ParseDice(expr)
+	if err != nil {
+		return 0, err
+	}
+	return d.Roll(p), nil
+}
+
 func (p PRNG) RollD100() int {
 	return p.IntN(100) + 1
 }
@@ -116,11 +191,11 @@ func (p PRNG) GenXYZ() Coordinates {
 // The zone defines a shell based on percentages.
 func (p PRNG) GenZonedXYZ(minPct, maxPct float64) Coordinates {
 	// generate a random distance with a uniform distribution between the zone's minimum and maximum values
-	d := math.Cbrt(rand.Float64()*(math.Pow(maxPct, 3)-math.Pow(minPct, 3)) + math.Pow(minPct, 3))
+	d := math.Cbrt(p.Float64()*(math.Pow(maxPct, 3)-math.Pow(minPct, 3)) + math.Pow(minPct, 3))
 
 	// generate random angles for spherical coordinates
-	theta := rand.Float64() * 2 * math.Pi  // 0 to 2π
-	phi := math.Acos(2*rand.Float64() - 1) // 0 to π
+	theta := p.Float64() * 2 * math.Pi  // 0 to 2π
+	phi := math.Acos(2*p.Float64() - 1) // 0 to π
 
 	// convert spherical coordinates to Cartesian coordinates
 	return Coordinates{
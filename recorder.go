@@ -0,0 +1,116 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Recorder records every draw made from a PRNG, along with the label of the rule
+// that consumed it. The log can be dumped to JSON to trace how a catalog was generated.
+//
+// Only the Uint64, IntN and Float64 draws are recorded; every roll in this package is
+// built from them. A Recorder is not safe for concurrent use.
+type Recorder struct {
+	Rolls []Roll_t `json:"rolls"`
+}
+
+// NewRecorder returns an empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Len returns the number of draws recorded.
+func (r *Recorder) Len() int {
+	return len(r.Rolls)
+}
+
+// Reset discards all the draws recorded.
+func (r *Recorder) Reset() {
+	r.Rolls = nil
+}
+
+// WriteJSON writes the draws recorded as indented JSON.
+func (r *Recorder) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// ReadRecorder reads a log of draws that was written by WriteJSON.
+func ReadRecorder(rd io.Reader) (*Recorder, error) {
+	r := &Recorder{}
+	if err := json.NewDecoder(rd).Decode(r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Find returns the index of every draw with the label.
+func (r *Recorder) Find(label string) []int {
+	var found []int
+	for n, roll := range r.Rolls {
+		if roll.Label == label {
+			found = append(found, n)
+		}
+	}
+	return found
+}
+
+// record appends a draw to the log.
+func (r *Recorder) record(roll Roll_t) {
+	r.Rolls = append(r.Rolls, roll)
+}
+
+// Roll_t is a single draw from a PRNG.
+type Roll_t struct {
+	Label  string     `json:"label,omitempty"`  // the rule that consumed the draw
+	Kind   RollKind_e `json:"kind"`             // the method that made the draw
+	N      int        `json:"n,omitempty"`      // the argument to IntN
+	Result uint64     `json:"result,omitempty"` // the result of a Uint64 or IntN draw
+	Float  float64    `json:"float,omitempty"`  // the result of a Float64 draw
+}
+
+// RollKind_e is the method used to draw from a PRNG.
+type RollKind_e int
+
+const (
+	Uint64Roll RollKind_e = iota
+	IntNRoll
+	Float64Roll
+)
+
+// String implements the Stringer interface.
+func (k RollKind_e) String() string {
+	switch k {
+	case Uint64Roll:
+		return "uint64"
+	case IntNRoll:
+		return "intn"
+	case Float64Roll:
+		return "float64"
+	}
+	return fmt.Sprintf("RollKind_e(%d)", int(k))
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (k RollKind_e) MarshalText() ([]byte, error) {
+	switch k {
+	case Uint64Roll, IntNRoll, Float64Roll:
+		return []byte(k.String()), nil
+	}
+	return nil, fmt.Errorf("%d: %w", int(k), ErrInvalidRollKind)
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (k *RollKind_e) UnmarshalText(text []byte) error {
+	for _, kind := range []RollKind_e{Uint64Roll, IntNRoll, Float64Roll} {
+		if string(text) == kind.String() {
+			*k = kind
+			return nil
+		}
+	}
+	return fmt.Errorf("%q: %w", string(text), ErrInvalidRollKind)
+}
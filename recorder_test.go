@@ -0,0 +1,130 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/mdhender/aow"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestRecorder_RecordsDraws(t *testing.T) {
+	r := aow.NewRecorder()
+	p := aow.NewPRNG(rand.NewPCG(0xcafe, 0xcafe)).WithRecorder(r) // Use a fixed seed for reproducibility
+	q := aow.NewPRNG(rand.NewPCG(0xcafe, 0xcafe))
+
+	if a, b := p.WithLabel("seed").Uint64(), q.Uint64(); a != b {
+		t.Errorf("Uint64() = %d, want %d", a, b)
+	}
+	if a, b := p.WithLabel("3d6").RollD6(3), q.RollD6(3); a != b {
+		t.Errorf("RollD6(3) = %f, want %f", a, b)
+	}
+	if a, b := p.RollPercentile(), q.RollPercentile(); a != b {
+		t.Errorf("RollPercentile() = %f, want %f", a, b)
+	}
+
+	if r.Len() != 5 {
+		t.Fatalf("Recorder recorded %d draws, want 5", r.Len())
+	}
+	for n, expected := range []struct {
+		label string
+		kind  aow.RollKind_e
+	}{
+		{"seed", aow.Uint64Roll},
+		{"3d6", aow.IntNRoll},
+		{"3d6", aow.IntNRoll},
+		{"3d6", aow.IntNRoll},
+		{"", aow.Float64Roll},
+	} {
+		if roll := r.Rolls[n]; roll.Label != expected.label || roll.Kind != expected.kind {
+			t.Errorf("roll %d = %q %v, want %q %v", n, roll.Label, roll.Kind, expected.label, expected.kind)
+		}
+	}
+	for _, roll := range r.Rolls[1:4] {
+		if roll.N != 6 || roll.Result > 5 {
+			t.Errorf("roll = IntN(%d) %d, want IntN(6) between 0 and 5", roll.N, roll.Result)
+		}
+	}
+	if r.Rolls[4].Float < 0 || r.Rolls[4].Float >= 1 {
+		t.Errorf("roll = Float64 %f, want between 0 and 1", r.Rolls[4].Float)
+	}
+}
+
+func TestRecorder_JSON(t *testing.T) {
+	r := aow.NewRecorder()
+	p := aow.NewPRNG(rand.NewPCG(0xcafe, 0xcafe)).WithRecorder(r) // Use a fixed seed for reproducibility
+	p.WithLabel("seed").Uint64()
+	p.WithLabel("d100").RollD100()
+	p.WithLabel("percentile").RollPercentile()
+
+	var buf bytes.Buffer
+	if err := r.WriteJSON(&buf); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"kind": "intn"`)) {
+		t.Errorf("WriteJSON() = %s, want kinds written as names", buf.String())
+	}
+	loaded, err := aow.ReadRecorder(&buf)
+	if err != nil {
+		t.Fatalf("ReadRecorder() error = %v", err)
+	}
+	if a, b := mustMarshal(t, r), mustMarshal(t, loaded); !bytes.Equal(a, b) {
+		t.Errorf("ReadRecorder() = %s, want %s", b, a)
+	}
+
+	if _, err := aow.ReadRecorder(bytes.NewBufferString(`{"rolls":[{"kind":"d20"}]}`)); !errors.Is(err, aow.ErrInvalidRollKind) {
+		t.Errorf("ReadRecorder() error = %v, want %v", err, aow.ErrInvalidRollKind)
+	}
+}
+
+func TestGenerator_WithRecorder(t *testing.T) {
+	if _, err := aow.New(100, rand.NewPCG(0xcafe, 0xcafe), aow.SurveyCatalog, aow.WithRecorder(nil)); err != aow.ErrRecorderNil {
+		t.Errorf("New() error = %v, want %v", err, aow.ErrRecorderNil)
+	}
+
+	generate := func(options ...aow.Option) []byte {
+		g, err := aow.New(100, rand.NewPCG(0xcafe, 0xcafe), aow.SurveyCatalog, options...)
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		if err := g.BackgroundPopulation(); err != nil {
+			t.Fatalf("BackgroundPopulation() error = %v", err)
+		}
+		cluster, err := g.OpenCluster(aow.Coordinates{})
+		if err != nil {
+			t.Fatalf("OpenCluster() error = %v", err)
+		}
+		g.Catalog.MergeCluster(cluster)
+		return mustMarshal(t, g.Catalog)
+	}
+
+	r := aow.NewRecorder()
+	if a, b := generate(), generate(aow.WithRecorder(r)); !bytes.Equal(a, b) {
+		t.Errorf("recording changed the catalog")
+	}
+
+	labels := make(map[string]int)
+	for _, roll := range r.Rolls {
+		labels[roll.Label]++
+	}
+	for _, label := range []string{"generator seed", "catalog seed", "cluster age table", "stellar mass table", "orbital spacing"} {
+		if labels[label] == 0 {
+			t.Errorf("recorder has no draws labeled %q", label)
+		}
+	}
+	if labels[""] != 0 {
+		t.Errorf("recorder has %d unlabeled draws, want 0", labels[""])
+	}
+}
+
+func mustMarshal(t *testing.T, v any) []byte {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	return data
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow
+
+import "math"
+
+const (
+	// neutronStarMassLimit is the smallest initial mass (in solar masses) that ends as a neutron star.
+	neutronStarMassLimit = 8.0
+	// blackHoleMassLimit is the smallest initial mass (in solar masses) that ends as a black hole.
+	blackHoleMassLimit = 20.0
+)
+
+// RemnantState returns the state of the remnant left behind when a star dies.
+//
+// Parameters:
+//   - initialMass: The initial mass of the star, in solar masses.
+//   - coolingAge: The time since the star left the giant phase, in billions of years.
+//
+// Returns:
+//   - A white dwarf for stars with an initial mass below 8 solar masses,
+//     a neutron star for stars below 20 solar masses, and a black hole otherwise.
+func RemnantState(initialMass, coolingAge float64) StellarState_t {
+	switch {
+	case initialMass < neutronStarMassLimit:
+		return whiteDwarfState(initialMass, coolingAge)
+	case initialMass < blackHoleMassLimit:
+		return neutronStarState(initialMass, coolingAge)
+	}
+	return blackHoleState(initialMass)
+}
+
+// whiteDwarfState returns the state of a white dwarf that has been cooling for the given time (in billions of years).
+// The mass comes from the initial-final mass relation and the luminosity from a simple cooling curve.
+func whiteDwarfState(initialMass, coolingAge float64) StellarState_t {
+	mass := math.Min(0.109*initialMass+0.394, 1.35)
+	s := StellarState_t{
+		Phase:      WhiteDwarfPhase,
+		Mass:       mass,
+		Luminosity: 1e-3 * mass * math.Pow(math.Max(coolingAge, 0.001), -1.4),
+		Radius:     0.0126 * math.Pow(mass, -1.0/3.0),
+	}
+	s.Temperature = temperatureOf(s.Luminosity, s.Radius)
+	s.SpectralClass = spectralClass(s.Temperature, s.Phase, initialMass)
+	return s
+}
+
+// neutronStarState returns the state of a neutron star that has been cooling for the given time (in billions of years).
+func neutronStarState(initialMass, coolingAge float64) StellarState_t {
+	s := StellarState_t{
+		Phase:       NeutronStarPhase,
+		Mass:        math.Min(1.2+0.04*(initialMass-neutronStarMassLimit), 2.0),
+		Radius:      1.44e-5, // about 10 km
+		Temperature: math.Max(1e6*math.Pow(math.Max(coolingAge, 1e-5)/1e-5, -0.25), 1e4),
+	}
+	s.Luminosity = s.Radius * s.Radius * math.Pow(s.Temperature/solarTemperature, 4)
+	return s
+}
+
+// blackHoleState returns the state of a black hole. An isolated black hole has no luminosity,
+// and the radius is the Schwarzschild radius.
+func blackHoleState(initialMass float64) StellarState_t {
+	mass := 0.3 * initialMass
+	return StellarState_t{
+		Phase:  BlackHolePhase,
+		Mass:   mass,
+		Radius: 4.24e-6 * mass,
+	}
+}
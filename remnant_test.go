@@ -0,0 +1,68 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow_test
+
+import (
+	"github.com/mdhender/aow"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestRemnantState(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		initialMass float64
+		phase       aow.StellarPhase_e
+	}{
+		{"white dwarf", 1.0, aow.WhiteDwarfPhase},
+		{"massive white dwarf", 7.9, aow.WhiteDwarfPhase},
+		{"neutron star", 12.0, aow.NeutronStarPhase},
+		{"black hole", 25.0, aow.BlackHolePhase},
+	} {
+		result := aow.RemnantState(tc.initialMass, 1.0)
+		if result.Phase != tc.phase {
+			t.Errorf("%s: RemnantState(%f, 1) phase = %v, want %v", tc.name, tc.initialMass, result.Phase, tc.phase)
+		}
+		if !result.IsRemnant() {
+			t.Errorf("%s: RemnantState(%f, 1) is not a remnant", tc.name, tc.initialMass)
+		}
+		if result.Mass >= tc.initialMass {
+			t.Errorf("%s: RemnantState(%f, 1) mass = %f, want less than initial mass", tc.name, tc.initialMass, result.Mass)
+		}
+	}
+}
+
+func TestRemnantState_WhiteDwarfCooling(t *testing.T) {
+	young, old := aow.RemnantState(1.0, 0.1), aow.RemnantState(1.0, 10)
+	if old.Luminosity >= young.Luminosity || old.Temperature >= young.Temperature {
+		t.Errorf("RemnantState(1, 10) = %+v, want cooler and dimmer than %+v", old, young)
+	}
+}
+
+func TestNewBackgroundPopulation_Remnants(t *testing.T) {
+	p := aow.NewPRNG(rand.NewPCG(0xcafe, 0xcafe)) // Use a fixed seed for reproducibility
+	catalog, err := aow.NewBackgroundPopulation(aow.PopulationModelForSolLikeNeighborhood(2_000, 0), p)
+	if err != nil {
+		t.Fatalf("NewBackgroundPopulation() error = %v", err)
+	}
+	var remnants int
+	for _, ss := range catalog.StarSystems {
+		if ss.Primary().IsRemnant() {
+			remnants++
+		}
+	}
+	if remnants == 0 {
+		t.Errorf("NewBackgroundPopulation() generated no remnants")
+	}
+}
+
+func TestNewBackgroundPopulation_Empty(t *testing.T) {
+	p := aow.NewPRNG(rand.NewPCG(0xcafe, 0xcafe))
+	catalog, err := aow.NewBackgroundPopulation(aow.PopulationModel_t{}, p)
+	if err != nil {
+		t.Fatalf("NewBackgroundPopulation() error = %v", err)
+	}
+	if len(catalog.StarSystems) != 0 {
+		t.Errorf("NewBackgroundPopulation() generated %d systems, want 0", len(catalog.StarSystems))
+	}
+}
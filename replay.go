@@ -0,0 +1,112 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow
+
+import (
+	"fmt"
+	"math/bits"
+	"math/rand/v2"
+)
+
+// ReplaySource is a rand.Source that replays a log of draws recorded by a Recorder.
+// Individual draws can be overridden to force a table result while keeping every
+// other draw identical.
+//
+// A PRNG created from a ReplaySource shares the source with every PRNG derived from it,
+// so a generator replays the whole log in the order it was recorded. If an override
+// changes the number of draws that follow it (a larger cluster, say), the rest of the log
+// no longer lines up with the rules that consume it; the draws are still valid, but they
+// aren't the ones recorded. Once the log runs out, the source draws from the fallback.
+type ReplaySource struct {
+	rolls    []Roll_t
+	next     int
+	fallback rand.Source
+}
+
+// NewReplaySource returns a source that replays the draws in the recorder.
+// If fallback is nil, the source panics when the log runs out.
+func NewReplaySource(r *Recorder, fallback rand.Source) *ReplaySource {
+	return &ReplaySource{
+		rolls:    append([]Roll_t(nil), r.Rolls...),
+		fallback: fallback,
+	}
+}
+
+// Uint64 implements the rand.Source interface.
+// It returns the raw value that makes the PRNG repeat the next draw in the log.
+func (s *ReplaySource) Uint64() uint64 {
+	if s.next == len(s.rolls) {
+		if s.fallback == nil {
+			panic(fmt.Sprintf("aow: replay log exhausted after %d draws", s.next))
+		}
+		return s.fallback.Uint64()
+	}
+	roll := s.rolls[s.next]
+	s.next++
+	return roll.raw()
+}
+
+// Remaining returns the number of draws in the log that haven't been replayed.
+func (s *ReplaySource) Remaining() int {
+	return len(s.rolls) - s.next
+}
+
+// OverrideResult replaces the result of the i'th draw, which must be a Uint64 or IntN draw.
+// For an IntN draw, the result must be less than the argument to IntN.
+func (s *ReplaySource) OverrideResult(i int, result uint64) error {
+	if i < 0 || i >= len(s.rolls) {
+		return fmt.Errorf("draw %d of %d: %w", i, len(s.rolls), ErrInvalidOverride)
+	}
+	switch roll := &s.rolls[i]; roll.Kind {
+	case Uint64Roll:
+		roll.Result = result
+	case IntNRoll:
+		if result >= uint64(roll.N) {
+			return fmt.Errorf("draw %d: %d is not less than %d: %w", i, result, roll.N, ErrInvalidOverride)
+		}
+		roll.Result = result
+	default:
+		return fmt.Errorf("draw %d: can't set the result of a %s draw: %w", i, roll.Kind, ErrInvalidOverride)
+	}
+	return nil
+}
+
+// OverrideFloat replaces the result of the i'th draw, which must be a Float64 draw.
+// The result must be in the half-open interval [0.0,1.0).
+func (s *ReplaySource) OverrideFloat(i int, result float64) error {
+	if i < 0 || i >= len(s.rolls) {
+		return fmt.Errorf("draw %d of %d: %w", i, len(s.rolls), ErrInvalidOverride)
+	} else if s.rolls[i].Kind != Float64Roll {
+		return fmt.Errorf("draw %d: can't set the float of a %s draw: %w", i, s.rolls[i].Kind, ErrInvalidOverride)
+	} else if !(0 <= result && result < 1) {
+		return fmt.Errorf("draw %d: %g is not in [0,1): %w", i, result, ErrInvalidOverride)
+	}
+	s.rolls[i].Float = result
+	return nil
+}
+
+// raw returns the value from the source that makes math/rand/v2 return the result of the draw.
+//
+// This depends on how math/rand/v2 turns values from the source into results, which the
+// package doesn't document: IntN uses Lemire's multiply-and-shift method (masking for
+// powers of two) and Float64 uses the low 53 bits of one value. If a future release of
+// the standard library changes either, replays will quietly return different results.
+// TestReplaySource_MatchesMathRand checks raw against the math/rand/v2 in use.
+func (roll Roll_t) raw() uint64 {
+	switch roll.Kind {
+	case IntNRoll:
+		n := uint64(roll.N)
+		if n&(n-1) == 0 {
+			// powers of two are masked
+			return roll.Result
+		}
+		// IntN returns the high word of raw * n, so pick the raw value in the middle of
+		// the range that maps to the result. Its low word is far from the rejection threshold.
+		raw, _ := bits.Div64(roll.Result, 1<<63, n)
+		return raw
+	case Float64Roll:
+		// Float64 uses the low 53 bits of the raw value
+		return uint64(roll.Float * (1 << 53))
+	}
+	return roll.Result
+}
@@ -0,0 +1,164 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow_test
+
+import (
+	"bytes"
+	"errors"
+	"github.com/mdhender/aow"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestReplaySource_RepeatsDraws(t *testing.T) {
+	r := aow.NewRecorder()
+	p := aow.NewPRNG(rand.NewPCG(0xcafe, 0xcafe)).WithRecorder(r) // Use a fixed seed for reproducibility
+	var expected []float64
+	for n := 0; n < 1_000; n++ {
+		expected = append(expected, float64(p.Uint64()), float64(p.IntN(6)), float64(p.IntN(100)), float64(p.IntN(1<<20)), p.Float64())
+	}
+
+	q := aow.NewPRNG(aow.NewReplaySource(r, nil))
+	for n := 0; n < len(expected); n += 5 {
+		result := []float64{float64(q.Uint64()), float64(q.IntN(6)), float64(q.IntN(100)), float64(q.IntN(1 << 20)), q.Float64()}
+		for i, v := range result {
+			if v != expected[n+i] {
+				t.Fatalf("draw %d = %g, want %g", n+i, v, expected[n+i])
+			}
+		}
+	}
+}
+
+func TestReplaySource_Generator(t *testing.T) {
+	generate := func(prng rand.Source, options ...aow.Option) []byte {
+		g, err := aow.New(100, prng, aow.SurveyCatalog, options...)
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		if err := g.BackgroundPopulation(); err != nil {
+			t.Fatalf("BackgroundPopulation() error = %v", err)
+		}
+		cluster, err := g.OpenCluster(aow.Coordinates{})
+		if err != nil {
+			t.Fatalf("OpenCluster() error = %v", err)
+		}
+		g.Catalog.MergeCluster(cluster)
+		return mustMarshal(t, g.Catalog)
+	}
+
+	r := aow.NewRecorder()
+	expected := generate(rand.NewPCG(0xcafe, 0xcafe), aow.WithRecorder(r))
+	src := aow.NewReplaySource(r, nil)
+	if result := generate(src); !bytes.Equal(result, expected) {
+		t.Errorf("replay produced a different catalog")
+	}
+	if src.Remaining() != 0 {
+		t.Errorf("replay left %d draws, want 0", src.Remaining())
+	}
+}
+
+func TestReplaySource_OverrideOpenCluster(t *testing.T) {
+	r := aow.NewRecorder()
+	if _, err := aow.NewOpenCluster(aow.NewSeededPRNG(0xcafe).WithRecorder(r)); err != nil {
+		t.Fatalf("NewOpenCluster() error = %v", err)
+	}
+	binding, age := r.Find("cluster binding"), r.Find("cluster age table")
+	if len(binding) != 3 || len(age) != 2 {
+		t.Fatalf("recorded %d binding and %d age draws, want 3 and 2", len(binding), len(age))
+	}
+
+	// force a tightly bound cluster by rolling 3 on 3d6, then roll 100 on the age table
+	src := aow.NewReplaySource(r, rand.NewPCG(0xcafe, 0xcafe))
+	for _, n := range binding {
+		if err := src.OverrideResult(n, 0); err != nil {
+			t.Fatalf("OverrideResult(%d) error = %v", n, err)
+		}
+	}
+	if err := src.OverrideResult(age[0], 99); err != nil {
+		t.Fatalf("OverrideResult(%d) error = %v", age[0], err)
+	}
+	cluster, err := aow.NewOpenCluster(aow.NewPRNG(src))
+	if err != nil {
+		t.Fatalf("NewOpenCluster() error = %v", err)
+	}
+	if cluster.Catalog.Length() == 0 {
+		t.Fatalf("NewOpenCluster() has no star systems")
+	}
+	for _, ss := range cluster.Catalog.StarSystems {
+		// the oldest row of the tightly bound table is 3 to 8 billion years
+		if ss.Age < 0.95*3.0 || ss.Population == aow.YoungPopulationI {
+			t.Errorf("NewOpenCluster() system age %f population %v, want the oldest row of the tightly bound table", ss.Age, ss.Population)
+		}
+	}
+}
+
+func TestReplaySource_OverrideErrors(t *testing.T) {
+	r := aow.NewRecorder()
+	p := aow.NewPRNG(rand.NewPCG(0xcafe, 0xcafe)).WithRecorder(r) // Use a fixed seed for reproducibility
+	p.Uint64()
+	p.IntN(6)
+	p.Float64()
+
+	src := aow.NewReplaySource(r, nil)
+	for _, err := range []error{
+		src.OverrideResult(-1, 0),
+		src.OverrideResult(3, 0),
+		src.OverrideResult(1, 6),
+		src.OverrideResult(2, 0),
+		src.OverrideFloat(0, 0.5),
+		src.OverrideFloat(2, 1.0),
+	} {
+		if !errors.Is(err, aow.ErrInvalidOverride) {
+			t.Errorf("override error = %v, want %v", err, aow.ErrInvalidOverride)
+		}
+	}
+	if err := src.OverrideFloat(2, 0.25); err != nil {
+		t.Errorf("OverrideFloat() error = %v", err)
+	}
+	q := aow.NewPRNG(src)
+	q.Uint64()
+	q.IntN(6)
+	if f := q.Float64(); f != 0.25 {
+		t.Errorf("Float64() = %f, want 0.25", f)
+	}
+}
+
+func TestReplaySource_MatchesMathRand(t *testing.T) {
+	// record many draws of every kind, over small, large and power of two ranges
+	r := aow.NewRecorder()
+	p := aow.NewPRNG(rand.NewPCG(0xcafe, 0xcafe)).WithRecorder(r) // Use a fixed seed for reproducibility
+	sizes := aow.NewSeededPRNG(0xbeef)
+	for n := 0; n < 10_000; n++ {
+		switch n % 5 {
+		case 0:
+			p.IntN(1 + sizes.IntN(100))
+		case 1:
+			p.IntN(1 + sizes.IntN(1<<62))
+		case 2:
+			p.IntN(1 << sizes.IntN(63))
+		case 3:
+			p.Float64()
+		default:
+			p.Uint64()
+		}
+	}
+
+	// a live math/rand/v2 generator reading the replay source returns the same results
+	live := rand.New(aow.NewReplaySource(r, nil))
+	for n, roll := range r.Rolls {
+		switch roll.Kind {
+		case aow.IntNRoll:
+			if result := live.IntN(roll.N); uint64(result) != roll.Result {
+				t.Fatalf("draw %d: IntN(%d) = %d, want %d", n, roll.N, result, roll.Result)
+			}
+		case aow.Float64Roll:
+			if result := live.Float64(); result != roll.Float {
+				t.Fatalf("draw %d: Float64() = %v, want %v", n, result, roll.Float)
+			}
+		default:
+			if result := live.Uint64(); result != roll.Result {
+				t.Fatalf("draw %d: Uint64() = %d, want %d", n, result, roll.Result)
+			}
+		}
+	}
+}
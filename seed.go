@@ -0,0 +1,57 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow
+
+import "math/rand/v2"
+
+// Seeds are derived hierarchically so that every part of a catalog has its own
+// independent stream of random numbers. The generator's seed is split into a seed
+// for the background population and a seed for each cluster and association; each catalog's seed
+// is split into a seed for each star system by index; and each star system's seed
+// is split into a seed for each generation step. Changing the number of clusters,
+// or adding a new generation step, doesn't change any existing star system.
+
+// keys used to derive seeds for the parts of a catalog
+const (
+	seedKeyBackground uint64 = iota + 1
+	seedKeyOpenCluster
+	seedKeyStellarAssociation
+	seedKeyPlacement
+	seedKeyImport
+)
+
+// keys used to derive seeds for the generation steps of a star system
+const (
+	seedKeyStars uint64 = iota + 1
+	seedKeyOrbits
+	seedKeyGasGiants
+	seedKeyTerrestrialPlanets
+	seedKeyAtmospheres
+	seedKeyID
+	seedKeyName
+)
+
+// DeriveSeed returns a new seed derived from the parent seed and the keys.
+// The same parent and keys always return the same seed, and different keys
+// return seeds that are statistically independent.
+func DeriveSeed(parent uint64, keys ...uint64) uint64 {
+	seed := splitmix64(parent)
+	for _, key := range keys {
+		seed = splitmix64(seed ^ splitmix64(key))
+	}
+	return seed
+}
+
+// NewSeededPRNG returns a PRNG that is initialized from a single seed.
+func NewSeededPRNG(seed uint64) PRNG {
+	return NewPRNG(rand.NewPCG(seed, splitmix64(seed)))
+}
+
+// splitmix64 is the finalizer from the SplitMix64 generator.
+// It scrambles the bits of the input so that nearby inputs give unrelated outputs.
+func splitmix64(x uint64) uint64 {
+	x += 0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	return x ^ (x >> 31)
+}
@@ -0,0 +1,70 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/mdhender/aow"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestDeriveSeed(t *testing.T) {
+	if aow.DeriveSeed(0xcafe, 1, 2) != aow.DeriveSeed(0xcafe, 1, 2) {
+		t.Errorf("DeriveSeed() is not repeatable")
+	}
+	seen := make(map[uint64]bool)
+	for i := uint64(0); i < 1_000; i++ {
+		seed := aow.DeriveSeed(0xcafe, i)
+		if seen[seed] {
+			t.Fatalf("DeriveSeed(0xcafe, %d) = %x, duplicate seed", i, seed)
+		}
+		seen[seed] = true
+	}
+	if aow.DeriveSeed(0xcafe, 1, 2) == aow.DeriveSeed(0xcafe, 2, 1) {
+		t.Errorf("DeriveSeed() ignores the order of the keys")
+	}
+}
+
+func TestStarSystem_Generate(t *testing.T) {
+	catalog, err := aow.NewBackgroundPopulation(aow.PopulationModelForSolLikeNeighborhood(200, 0), aow.NewPRNG(rand.NewPCG(0xcafe, 0xcafe)))
+	if err != nil {
+		t.Fatalf("NewBackgroundPopulation() error = %v", err)
+	}
+	for _, ss := range catalog.StarSystems {
+		// regenerate the system from nothing but its identity, seed and placement
+		regenerated := &aow.StarSystem_t{ID: ss.ID, Designation: ss.Designation, Seed: ss.Seed, Population: ss.Population, Age: ss.Age, Coordinates: ss.Coordinates}
+		regenerated.Generate()
+		a, _ := json.Marshal(ss)
+		b, _ := json.Marshal(regenerated)
+		if !bytes.Equal(a, b) {
+			t.Fatalf("Generate() regenerated system differs from the original")
+		}
+	}
+}
+
+func TestGenerator_ClustersDontChangeBackground(t *testing.T) {
+	background := func(clusters int) []byte {
+		g, err := aow.New(500, rand.NewPCG(0xcafe, 0xcafe), aow.SurveyCatalog)
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		for n := 0; n < clusters; n++ {
+			if _, err := g.OpenCluster(aow.Coordinates{}); err != nil {
+				t.Fatalf("OpenCluster() error = %v", err)
+			}
+		}
+		if err := g.BackgroundPopulation(); err != nil {
+			t.Fatalf("BackgroundPopulation() error = %v", err)
+		}
+		data, err := json.Marshal(g.Catalog)
+		if err != nil {
+			t.Fatalf("json.Marshal() error = %v", err)
+		}
+		return data
+	}
+	if !bytes.Equal(background(0), background(3)) {
+		t.Errorf("adding clusters changed the background population")
+	}
+}
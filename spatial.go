@@ -0,0 +1,217 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// Within returns the star systems within the radius (in parsecs) of the center,
+// nearest first.
+//
+// The spatial queries (Within, Nearest and InBox) use a k-d tree that is built on
+// the first query and rebuilt after the catalog's methods change it. If you change
+// StarSystems or the coordinates of a system directly, call Reindex before querying.
+// The queries are not safe for concurrent use with each other until the index is built.
+func (c *Catalog_t) Within(center Coordinates, radius float64) []*StarSystem_t {
+	var found []*StarSystem_t
+	t := c.spatialIndex()
+	t.within(0, len(t.systems), 0, center, radius, &found)
+	sortByDistanceFrom(found, center)
+	return found
+}
+
+// Nearest returns the k star systems nearest to the point, nearest first.
+// It returns fewer than k systems if the catalog is smaller than k.
+func (c *Catalog_t) Nearest(point Coordinates, k int) []*StarSystem_t {
+	if k <= 0 {
+		return nil
+	}
+	h := &nearestHeap_t{}
+	t := c.spatialIndex()
+	t.nearest(0, len(t.systems), 0, point, k, h)
+	found := make([]*StarSystem_t, len(h.items))
+	for n, item := range h.items {
+		found[n] = item.ss
+	}
+	sortByDistanceFrom(found, point)
+	return found
+}
+
+// InBox returns the star systems inside the axis-aligned box with the given corners.
+// Systems on the faces of the box are included. The order of the result is not specified.
+func (c *Catalog_t) InBox(min, max Coordinates) []*StarSystem_t {
+	var found []*StarSystem_t
+	t := c.spatialIndex()
+	t.inBox(0, len(t.systems), 0, min, max, &found)
+	return found
+}
+
+// Reindex discards the spatial index and the lookup index so that the next query rebuilds them.
+func (c *Catalog_t) Reindex() {
+	c.index, c.lookup = nil, nil
+}
+
+// spatialIndex returns the k-d tree for the catalog, building it if needed.
+// The tree is kept until Reindex is called.
+func (c *Catalog_t) spatialIndex() *kdTree_t {
+	if c.index == nil {
+		c.index = newKDTree(c.StarSystems)
+	}
+	return c.index
+}
+
+// kdTree_t is an implicit, balanced k-d tree. The root of a range of systems is the
+// median of the range on the splitting axis; systems before it are no greater on that
+// axis and systems after it are no less. The axes cycle through X, Y and Z.
+type kdTree_t struct {
+	systems []*StarSystem_t
+}
+
+// newKDTree builds a k-d tree from a copy of the list of systems.
+func newKDTree(systems []*StarSystem_t) *kdTree_t {
+	t := &kdTree_t{systems: append([]*StarSystem_t(nil), systems...)}
+	t.build(0, len(t.systems), 0)
+	return t
+}
+
+func (t *kdTree_t) build(lo, hi, axis int) {
+	if hi-lo <= 1 {
+		return
+	}
+	mid := (lo + hi) / 2
+	t.selectNth(lo, hi, mid, axis)
+	t.build(lo, mid, (axis+1)%3)
+	t.build(mid+1, hi, (axis+1)%3)
+}
+
+// selectNth partially sorts the range so that the n'th system is the one that would
+// be there if the range were sorted on the axis.
+func (t *kdTree_t) selectNth(lo, hi, n, axis int) {
+	s := t.systems
+	for hi-lo > 1 {
+		pivot := s[lo+(hi-lo)/2].Coordinates.axis(axis)
+		// three-way partition: [lo,lt) is less than the pivot, [lt,gt) is equal, and [gt,hi) is greater
+		lt, i, gt := lo, lo, hi
+		for i < gt {
+			switch v := s[i].Coordinates.axis(axis); {
+			case v < pivot:
+				s[lt], s[i] = s[i], s[lt]
+				lt, i = lt+1, i+1
+			case v > pivot:
+				gt--
+				s[i], s[gt] = s[gt], s[i]
+			default:
+				i++
+			}
+		}
+		if n < lt {
+			hi = lt
+		} else if n >= gt {
+			lo = gt
+		} else {
+			return
+		}
+	}
+}
+
+func (t *kdTree_t) within(lo, hi, axis int, center Coordinates, radius float64, found *[]*StarSystem_t) {
+	if lo >= hi {
+		return
+	}
+	mid := (lo + hi) / 2
+	ss := t.systems[mid]
+	if ss.Coordinates.DistanceTo(center) <= radius {
+		*found = append(*found, ss)
+	}
+	diff := center.axis(axis) - ss.Coordinates.axis(axis)
+	if diff <= radius {
+		t.within(lo, mid, (axis+1)%3, center, radius, found)
+	}
+	if diff >= -radius {
+		t.within(mid+1, hi, (axis+1)%3, center, radius, found)
+	}
+}
+
+func (t *kdTree_t) inBox(lo, hi, axis int, min, max Coordinates, found *[]*StarSystem_t) {
+	if lo >= hi {
+		return
+	}
+	mid := (lo + hi) / 2
+	ss := t.systems[mid]
+	c := ss.Coordinates
+	if min.X <= c.X && c.X <= max.X && min.Y <= c.Y && c.Y <= max.Y && min.Z <= c.Z && c.Z <= max.Z {
+		*found = append(*found, ss)
+	}
+	split := c.axis(axis)
+	if min.axis(axis) <= split {
+		t.inBox(lo, mid, (axis+1)%3, min, max, found)
+	}
+	if max.axis(axis) >= split {
+		t.inBox(mid+1, hi, (axis+1)%3, min, max, found)
+	}
+}
+
+func (t *kdTree_t) nearest(lo, hi, axis int, point Coordinates, k int, h *nearestHeap_t) {
+	if lo >= hi {
+		return
+	}
+	mid := (lo + hi) / 2
+	ss := t.systems[mid]
+	if d := ss.Coordinates.DistanceTo(point); len(h.items) < k {
+		heap.Push(h, nearestItem_t{ss: ss, distance: d})
+	} else if d < h.items[0].distance {
+		h.items[0] = nearestItem_t{ss: ss, distance: d}
+		heap.Fix(h, 0)
+	}
+
+	// search the side of the split that holds the point first
+	diff := point.axis(axis) - ss.Coordinates.axis(axis)
+	near, far := [2]int{lo, mid}, [2]int{mid + 1, hi}
+	if diff > 0 {
+		near, far = far, near
+	}
+	t.nearest(near[0], near[1], (axis+1)%3, point, k, h)
+	if len(h.items) < k || diff*diff < h.items[0].distance*h.items[0].distance {
+		t.nearest(far[0], far[1], (axis+1)%3, point, k, h)
+	}
+}
+
+// nearestHeap_t is a max-heap of the nearest systems found so far.
+type nearestHeap_t struct {
+	items []nearestItem_t
+}
+
+type nearestItem_t struct {
+	ss       *StarSystem_t
+	distance float64
+}
+
+func (h *nearestHeap_t) Len() int           { return len(h.items) }
+func (h *nearestHeap_t) Less(i, j int) bool { return h.items[i].distance > h.items[j].distance }
+func (h *nearestHeap_t) Swap(i, j int)      { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *nearestHeap_t) Push(x any)         { h.items = append(h.items, x.(nearestItem_t)) }
+func (h *nearestHeap_t) Pop() any {
+	item := h.items[len(h.items)-1]
+	h.items = h.items[:len(h.items)-1]
+	return item
+}
+
+// sortByDistanceFrom sorts the systems by their distance from the point, nearest first.
+func sortByDistanceFrom(systems []*StarSystem_t, point Coordinates) {
+	sort.SliceStable(systems, func(i, j int) bool {
+		return systems[i].Coordinates.DistanceTo(point) < systems[j].Coordinates.DistanceTo(point)
+	})
+}
+
+// axis returns the X, Y or Z coordinate for the axis 0, 1 or 2.
+func (c Coordinates) axis(axis int) float64 {
+	switch axis {
+	case 0:
+		return c.X
+	case 1:
+		return c.Y
+	}
+	return c.Z
+}
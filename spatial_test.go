@@ -0,0 +1,134 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow_test
+
+import (
+	"github.com/mdhender/aow"
+	"math/rand/v2"
+	"testing"
+)
+
+// randomCatalog returns a catalog of systems with random coordinates and no stars.
+func randomCatalog(p aow.PRNG, n int, radius float64) *aow.Catalog_t {
+	c := &aow.Catalog_t{Radius: radius}
+	for i := 0; i < n; i++ {
+		coordinates := p.GenXYZ().Scale(radius)
+		if i%10 == 0 {
+			// make sure the index copes with systems that share a coordinate
+			coordinates.X = 1
+		}
+		c.StarSystems = append(c.StarSystems, &aow.StarSystem_t{Seed: uint64(i), Coordinates: coordinates})
+	}
+	return c
+}
+
+func TestCatalog_Within(t *testing.T) {
+	p := aow.NewPRNG(rand.NewPCG(0xcafe, 0xcafe)) // Use a fixed seed for reproducibility
+	c := randomCatalog(p, 5_000, 50)
+	for n := 0; n < 50; n++ {
+		center, radius := p.GenXYZ().Scale(60), 1+10*p.Float64()
+		result := c.Within(center, radius)
+		var expected int
+		for _, ss := range c.StarSystems {
+			if ss.Coordinates.DistanceTo(center) <= radius {
+				expected++
+			}
+		}
+		if len(result) != expected {
+			t.Errorf("Within(%v, %f) found %d systems, want %d", center, radius, len(result), expected)
+		}
+		for i, ss := range result {
+			if d := ss.Coordinates.DistanceTo(center); d > radius {
+				t.Errorf("Within(%v, %f) found a system at %f", center, radius, d)
+			} else if i > 0 && d < result[i-1].Coordinates.DistanceTo(center) {
+				t.Errorf("Within(%v, %f) is not sorted by distance", center, radius)
+			}
+		}
+	}
+}
+
+func TestCatalog_Nearest(t *testing.T) {
+	p := aow.NewPRNG(rand.NewPCG(0xcafe, 0xcafe)) // Use a fixed seed for reproducibility
+	c := randomCatalog(p, 5_000, 50)
+	for n := 0; n < 50; n++ {
+		point, k := p.GenXYZ().Scale(60), 1+p.IntN(20)
+		result := c.Nearest(point, k)
+		if len(result) != k {
+			t.Fatalf("Nearest(%v, %d) found %d systems", point, k, len(result))
+		}
+		// the k'th nearest system must be no further than any system that wasn't returned
+		found := make(map[*aow.StarSystem_t]bool)
+		for _, ss := range result {
+			found[ss] = true
+		}
+		furthest := result[k-1].Coordinates.DistanceTo(point)
+		for _, ss := range c.StarSystems {
+			if !found[ss] && ss.Coordinates.DistanceTo(point) < furthest {
+				t.Errorf("Nearest(%v, %d) missed a system at %f, furthest found %f", point, k, ss.Coordinates.DistanceTo(point), furthest)
+			}
+		}
+	}
+	if result := c.Nearest(aow.Coordinates{}, 10_000); len(result) != c.Length() {
+		t.Errorf("Nearest(k > length) found %d systems, want %d", len(result), c.Length())
+	}
+	if result := c.Nearest(aow.Coordinates{}, 0); len(result) != 0 {
+		t.Errorf("Nearest(k = 0) found %d systems, want 0", len(result))
+	}
+}
+
+func TestCatalog_InBox(t *testing.T) {
+	p := aow.NewPRNG(rand.NewPCG(0xcafe, 0xcafe)) // Use a fixed seed for reproducibility
+	c := randomCatalog(p, 5_000, 50)
+	for n := 0; n < 50; n++ {
+		a, b := p.GenXYZ().Scale(50), p.GenXYZ().Scale(50)
+		lo := aow.Coordinates{X: min(a.X, b.X), Y: min(a.Y, b.Y), Z: min(a.Z, b.Z)}
+		hi := aow.Coordinates{X: max(a.X, b.X), Y: max(a.Y, b.Y), Z: max(a.Z, b.Z)}
+		if n == 0 {
+			// the box has a face on the shared coordinate
+			lo.X = 1
+		}
+		var expected int
+		for _, ss := range c.StarSystems {
+			s := ss.Coordinates
+			if lo.X <= s.X && s.X <= hi.X && lo.Y <= s.Y && s.Y <= hi.Y && lo.Z <= s.Z && s.Z <= hi.Z {
+				expected++
+			}
+		}
+		if result := c.InBox(lo, hi); len(result) != expected {
+			t.Errorf("InBox(%v, %v) found %d systems, want %d", lo, hi, len(result), expected)
+		}
+	}
+}
+
+func TestCatalog_IndexAfterChanges(t *testing.T) {
+	p := aow.NewPRNG(rand.NewPCG(0xcafe, 0xcafe)) // Use a fixed seed for reproducibility
+	c := randomCatalog(p, 1_000, 10)
+	other := randomCatalog(p, 100, 1)
+	offset := aow.Coordinates{X: 100}
+
+	if result := c.Within(offset, 2); len(result) != 0 {
+		t.Fatalf("Within() found %d systems before Merge, want 0", len(result))
+	}
+	c.Merge(other, offset)
+	if result := c.Within(offset, 2); len(result) != other.Length() {
+		t.Errorf("Within() found %d systems after Merge, want %d", len(result), other.Length())
+	}
+
+	c.Filter(func(ss *aow.StarSystem_t) bool { return ss.Coordinates.X < 50 })
+	if result := c.Within(offset, 2); len(result) != 0 {
+		t.Errorf("Within() found %d systems after Filter, want 0", len(result))
+	}
+
+	// sorting doesn't change the contents of the index
+	c.SortByDistance(aow.Coordinates{X: 5})
+	if result := c.Nearest(aow.Coordinates{X: 5}, 1); result[0] != c.StarSystems[0] {
+		t.Errorf("Nearest() after SortByDistance = %v, want %v", result[0].Coordinates, c.StarSystems[0].Coordinates)
+	}
+
+	// changing a coordinate directly needs a Reindex
+	c.StarSystems[0].Coordinates = offset
+	c.Reindex()
+	if result := c.Within(offset, 0.1); len(result) != 1 {
+		t.Errorf("Within() found %d systems after Reindex, want 1", len(result))
+	}
+}
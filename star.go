@@ -0,0 +1,110 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow
+
+import "math"
+
+// brownDwarfMassLimit is the smallest mass (in solar masses) that can sustain hydrogen fusion.
+// Anything below this limit is a brown dwarf.
+const brownDwarfMassLimit = 0.08
+
+// Star_t is a single star (or brown dwarf) in a star system.
+type Star_t struct {
+	Mass        float64               `json:"mass"`        // initial mass, in solar masses
+	BrownDwarf  bool                  `json:"brownDwarf"`  // true if the mass roll produced a brown dwarf rather than a star
+	Orbit       StellarOrbit_t        `json:"orbit"`       // orbit of a companion; the zero value for the primary
+	State       StellarState_t        `json:"state"`       // current state of the star, derived from the mass and age of the system
+	Disc        Disc_t                `json:"disc"`        // the protoplanetary disc the planets formed in
+	Orbits      []Orbit_t             `json:"orbits"`      // planetary orbits, ordered from the innermost outward
+	Arrangement GasGiantArrangement_e `json:"arrangement"` // arrangement of the gas giants in the orbits
+}
+
+// IsPrimary returns true if the star is the primary of its system.
+func (s Star_t) IsPrimary() bool {
+	return s.Orbit.Separation == NoSeparation
+}
+
+// IsRemnant returns true if the star is a white dwarf, neutron star or black hole.
+func (s Star_t) IsRemnant() bool {
+	return s.State.IsRemnant()
+}
+
+// NewPrimaryStar generates the primary star for a star system.
+//
+// Parameters:
+//   - age: The age of the star system, in billions of years.
+//   - prng: The source of random numbers.
+//
+// Returns:
+//   - The primary star, with the mass rolled from the stellar mass table.
+func NewPrimaryStar(age float64, prng PRNG) Star_t {
+	return newPrimaryStar(age, 0, prng)
+}
+
+// newPrimaryStar implements NewPrimaryStar. The modifier is added to the stellar mass roll.
+func newPrimaryStar(age float64, modifier int, prng PRNG) Star_t {
+	mass := StellarMass(stellarMassAgeModifier(age)+modifier, prng)
+	return Star_t{
+		Mass:       mass,
+		BrownDwarf: mass < brownDwarfMassLimit,
+	}
+}
+
+// StellarMass rolls on the stellar mass table and returns a mass in solar masses.
+// The modifier is added to the d100 roll; positive modifiers favor lower masses.
+//
+// The table selects a mass range, and a second roll places the mass within that
+// range. The second roll is geometric so that each range is spread evenly in log(mass).
+func StellarMass(modifier int, prng PRNG) float64 {
+	prng = prng.WithLabel("stellar mass table")
+	roll := prng.RollD100() + modifier
+	if roll < 1 {
+		roll = 1
+	} else if roll > 100 {
+		roll = 100
+	}
+	for _, row := range stellarMassTable {
+		if roll <= row.roll {
+			return row.minMass * math.Pow(row.maxMass/row.minMass, prng.RollPercentile())
+		}
+	}
+	// not reached; the last row of the table covers a roll of 100
+	panic("assert(roll <= 100)")
+}
+
+// stellarMassAgeModifier returns the modifier to the stellar mass roll for a system of the given age.
+// Older populations are skewed towards lower-mass primaries.
+func stellarMassAgeModifier(age float64) int {
+	switch {
+	case age < 5.0:
+		return 0
+	case age < 8.0:
+		return 2
+	case age < 9.5:
+		return 4
+	default:
+		return 6
+	}
+}
+
+// stellarMassTable is the d100 table for stellar masses (in solar masses).
+// The last row produces brown dwarfs.
+var stellarMassTable = []struct {
+	roll             int
+	minMass, maxMass float64
+}{
+	{roll: 1, minMass: 3.00, maxMass: 25.0},
+	{roll: 2, minMass: 2.00, maxMass: 3.00},
+	{roll: 4, minMass: 1.50, maxMass: 2.00},
+	{roll: 7, minMass: 1.20, maxMass: 1.50},
+	{roll: 11, minMass: 1.00, maxMass: 1.20},
+	{roll: 16, minMass: 0.85, maxMass: 1.00},
+	{roll: 22, minMass: 0.70, maxMass: 0.85},
+	{roll: 30, minMass: 0.55, maxMass: 0.70},
+	{roll: 40, minMass: 0.42, maxMass: 0.55},
+	{roll: 52, minMass: 0.30, maxMass: 0.42},
+	{roll: 66, minMass: 0.20, maxMass: 0.30},
+	{roll: 80, minMass: 0.13, maxMass: 0.20},
+	{roll: 90, minMass: brownDwarfMassLimit, maxMass: 0.13},
+	{roll: 100, minMass: 0.015, maxMass: brownDwarfMassLimit},
+}
@@ -3,12 +3,97 @@
 package aow
 
 type StarSystem_t struct {
-	Population  StellarPopulation_e
-	Age         float64     // in billions of years?
-	Coordinates Coordinates // relative to center of the catalog
-	distance    float64     // working storage for some calculations
+	ID            SystemID_t          `json:"id"`          // stable identifier, derived from the seed
+	Designation   string              `json:"designation"` // human-readable catalog designation, like "AOW 0042"
+	Name          string              `json:"name"`        // procedurally generated name; empty unless the catalog was named
+	Seed          uint64              `json:"seed"`        // seed for the details of the system; see DeriveSeed
+	Population    StellarPopulation_e `json:"population"`
+	Age           float64             `json:"age"`           // in billions of years?
+	Coordinates   Coordinates         `json:"coordinates"`   // relative to center of the catalog
+	Stars         []Star_t            `json:"stars"`         // the primary star followed by any companions
+	InCluster     bool                `json:"inCluster"`     // true if the system is a member of an open cluster
+	Cluster       int                 `json:"cluster"`       // the ID of the cluster the system is a member of; 0 if none or the cluster wasn't numbered
+	ClusterZone   ClusterZone_e       `json:"clusterZone"`   // the zone of the cluster the system was generated in
+	InAssociation bool                `json:"inAssociation"` // true if the system is a member of a stellar association
+	Association   int                 `json:"association"`   // the ID of the association the system is a member of; 0 if none
+	MassModifier  int                 `json:"massModifier"`  // added to the stellar mass roll of the primary
+	distance      float64             // working storage for some calculations
 }
 
 func (ss *StarSystem_t) DistanceTo(os *StarSystem_t) float64 {
 	return ss.Coordinates.DistanceTo(os.Coordinates)
 }
+
+// Primary returns the primary star of the system, or nil if the system has no stars
+// (for example, a system that hasn't been generated yet).
+func (ss *StarSystem_t) Primary() *Star_t {
+	if len(ss.Stars) == 0 {
+		return nil
+	}
+	return &ss.Stars[0]
+}
+
+// IsMultiple returns true if the system has more than one star.
+func (ss *StarSystem_t) IsMultiple() bool {
+	return len(ss.Stars) > 1
+}
+
+// IsRemnant returns true if every star in the system is a stellar remnant.
+func (ss *StarSystem_t) IsRemnant() bool {
+	for _, star := range ss.Stars {
+		if !star.IsRemnant() {
+			return false
+		}
+	}
+	return len(ss.Stars) != 0
+}
+
+// HasEarthLikePlanet returns true if any star in the system has an Earth-like planet.
+func (ss *StarSystem_t) HasEarthLikePlanet() bool {
+	for _, star := range ss.Stars {
+		for _, orbit := range star.Orbits {
+			if orbit.Planet.IsEarthLike() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Generate generates the stars, planetary orbits and planets for the star system.
+//
+// The details depend only on the seed, population, age and mass modifier of the system, so a system
+// can be regenerated in isolation or generated lazily. Each step of the generation
+// draws from its own random stream, derived from the seed of the system.
+func (ss *StarSystem_t) Generate() {
+	ss.generate(PRNG{})
+}
+
+// generate generates the star system, recording draws to the recorder of the parent PRNG.
+// Only the recorder is taken from the parent; the draws come from the seed of the system.
+func (ss *StarSystem_t) generate(parent PRNG) {
+	ss.Stars = newStars(ss.Age, ss.MassModifier, parent.derive(DeriveSeed(ss.Seed, seedKeyStars)))
+	PlacePlanetaryOrbits(ss.Stars, parent.derive(DeriveSeed(ss.Seed, seedKeyOrbits)))
+	for n := range ss.Stars {
+		PlaceGasGiants(&ss.Stars[n], parent.derive(DeriveSeed(ss.Seed, seedKeyGasGiants, uint64(n))))
+		PlaceTerrestrialPlanets(&ss.Stars[n], parent.derive(DeriveSeed(ss.Seed, seedKeyTerrestrialPlanets, uint64(n))))
+		PlaceAtmospheres(&ss.Stars[n], parent.derive(DeriveSeed(ss.Seed, seedKeyAtmospheres, uint64(n))))
+	}
+}
+
+// clone returns a deep copy of the star system.
+func (ss *StarSystem_t) clone() *StarSystem_t {
+	nss := *ss
+	nss.Stars = append([]Star_t(nil), ss.Stars...)
+	for i := range nss.Stars {
+		nss.Stars[i].Disc.ForbiddenZones = append([]Zone_t(nil), ss.Stars[i].Disc.ForbiddenZones...)
+		nss.Stars[i].Orbits = append([]Orbit_t(nil), ss.Stars[i].Orbits...)
+		for j, orbit := range nss.Stars[i].Orbits {
+			if orbit.Planet != nil {
+				planet := *orbit.Planet
+				nss.Stars[i].Orbits[j].Planet = &planet
+			}
+		}
+	}
+	return &nss
+}
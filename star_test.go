@@ -0,0 +1,45 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow_test
+
+import (
+	"github.com/mdhender/aow"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestStellarMass(t *testing.T) {
+	p := aow.NewPRNG(rand.NewPCG(0xcafe, 0xcafe)) // Use a fixed seed for reproducibility
+	for _, modifier := range []int{-10, 0, 6, 200} {
+		for n := 0; n < 1_000; n++ {
+			result := aow.StellarMass(modifier, p)
+			if result < 0.015 || result >= 25 {
+				t.Errorf("StellarMass(%d) = %f, want between 0.015 and 25", modifier, result)
+			}
+		}
+	}
+}
+
+func TestNewPrimaryStar(t *testing.T) {
+	p := aow.NewPRNG(rand.NewPCG(0xcafe, 0xcafe)) // Use a fixed seed for reproducibility
+	var brownDwarfs int
+	var youngMass, oldMass float64
+	for n := 0; n < 1_000; n++ {
+		young, old := aow.NewPrimaryStar(1.0, p), aow.NewPrimaryStar(12.0, p)
+		for _, star := range []aow.Star_t{young, old} {
+			if star.BrownDwarf != (star.Mass < 0.08) {
+				t.Errorf("NewPrimaryStar() mass %f brown dwarf %v", star.Mass, star.BrownDwarf)
+			}
+			if star.BrownDwarf {
+				brownDwarfs++
+			}
+		}
+		youngMass, oldMass = youngMass+young.Mass, oldMass+old.Mass
+	}
+	if brownDwarfs == 0 {
+		t.Errorf("NewPrimaryStar() generated no brown dwarfs")
+	}
+	if oldMass >= youngMass {
+		t.Errorf("NewPrimaryStar() old mass %f, want less than young mass %f", oldMass, youngMass)
+	}
+}
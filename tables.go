@@ -0,0 +1,237 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// RangeTable_t is a lookup table that maps a roll to an outcome.
+// Each row covers the rolls from the row before it up to and including its own maximum.
+// Rolls below the first row use the first row, and rolls above the last row use the last row.
+type RangeTable_t[T any] struct {
+	Name string          `json:"name"`
+	Dice *Dice_t         `json:"dice,omitempty"` // the dice rolled on the table; nil if the table is indexed by a computed value
+	Rows []RangeRow_t[T] `json:"rows"`
+}
+
+// RangeRow_t is a row of a range table.
+type RangeRow_t[T any] struct {
+	Max     int `json:"max"` // the highest roll for the row
+	Outcome T   `json:"outcome"`
+}
+
+// Lookup returns the outcome of the row that covers the roll.
+func (t *RangeTable_t[T]) Lookup(roll int) T {
+	for _, row := range t.Rows {
+		if roll <= row.Max {
+			return row.Outcome
+		}
+	}
+	return t.Rows[len(t.Rows)-1].Outcome
+}
+
+// Roll rolls the dice for the table, adds the modifier, and returns the outcome.
+// It panics if the table doesn't have dice.
+func (t *RangeTable_t[T]) Roll(p PRNG, modifier int) T {
+	if t.Dice == nil {
+		panic(fmt.Sprintf("aow: table %q has no dice", t.Name))
+	}
+	return t.Lookup(t.Dice.Roll(p) + modifier)
+}
+
+// Validate returns an error if the table has no rows or the rows are out of order.
+func (t *RangeTable_t[T]) Validate() error {
+	if len(t.Rows) == 0 {
+		return fmt.Errorf("%q: no rows: %w", t.Name, ErrInvalidTable)
+	}
+	for n := 1; n < len(t.Rows); n++ {
+		if t.Rows[n].Max <= t.Rows[n-1].Max {
+			return fmt.Errorf("%q: row %d: max %d is not greater than %d: %w", t.Name, n+1, t.Rows[n].Max, t.Rows[n-1].Max, ErrInvalidTable)
+		}
+	}
+	if t.Dice != nil && t.Dice.Max() > t.Rows[len(t.Rows)-1].Max {
+		return fmt.Errorf("%q: %s can roll %d, past the last row: %w", t.Name, t.Dice, t.Dice.Max(), ErrInvalidTable)
+	}
+	return nil
+}
+
+// clone returns a copy of the table that doesn't share rows or dice with the original.
+// Outcomes are copied by value; see Tables_t.clone for outcomes that hold pointers.
+func (t RangeTable_t[T]) clone() RangeTable_t[T] {
+	t.Dice = t.Dice.clone()
+	t.Rows = append([]RangeRow_t[T](nil), t.Rows...)
+	return t
+}
+
+// Range_t is an outcome that is placed uniformly between a minimum and a maximum.
+type Range_t struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// Place returns the value that is the given percentile of the way from the minimum to the maximum.
+func (r Range_t) Place(percentile float64) float64 {
+	return r.Min + (r.Max-r.Min)*percentile
+}
+
+// ClusterZones_t is the fraction of the members of a cluster in each zone.
+type ClusterZones_t struct {
+	Core  float64 `json:"core"`  // the cluster core
+	Tidal float64 `json:"tidal"` // out to the tidal radius
+	Halo  float64 `json:"halo"`  // the extended halo
+}
+
+// Tables_t is the set of tables the generator uses.
+// The defaults are the tables from the book; see DefaultTables and LoadTables.
+type Tables_t struct {
+	// TightlyBoundClusterAge gives the range of the age (in billions of years) of a tightly bound cluster.
+	TightlyBoundClusterAge RangeTable_t[Range_t] `json:"tightlyBoundClusterAge"`
+	// LooselyBoundClusterAge gives the range of the age (in billions of years) of a loosely bound cluster.
+	LooselyBoundClusterAge RangeTable_t[Range_t] `json:"looselyBoundClusterAge"`
+	// ClusterEvaporation is indexed by the effective age of a cluster in tenths of a billion years.
+	ClusterEvaporation RangeTable_t[ClusterZones_t] `json:"clusterEvaporation"`
+	// StellarAssociation gives the kind, age, size and expansion of a stellar association.
+	StellarAssociation RangeTable_t[AssociationRules_t] `json:"stellarAssociation"`
+	// BasicPopulationModel is the density and age of each stellar population near Sol.
+	// The combined density is always the sum of the population densities.
+	BasicPopulationModel PopulationModel_t `json:"basicPopulationModel"`
+}
+
+// the tables from the book are shipped as data files
+var (
+	//go:embed tables/*.json
+	tablesFS embed.FS
+
+	defaultTablesOnce sync.Once
+	defaultTables     *Tables_t
+)
+
+// DefaultTables returns a copy of the tables from the book.
+// It panics if the embedded data files are invalid.
+func DefaultTables() *Tables_t {
+	return builtinTables().clone()
+}
+
+// tablesDocument_t is the document read by LoadTables.
+// Each table is decoded into a new value, so nothing is merged with the defaults.
+type tablesDocument_t struct {
+	TightlyBoundClusterAge *RangeTable_t[Range_t]            `json:"tightlyBoundClusterAge"`
+	LooselyBoundClusterAge *RangeTable_t[Range_t]            `json:"looselyBoundClusterAge"`
+	ClusterEvaporation     *RangeTable_t[ClusterZones_t]     `json:"clusterEvaporation"`
+	StellarAssociation     *RangeTable_t[AssociationRules_t] `json:"stellarAssociation"`
+	BasicPopulationModel   *PopulationModel_t                `json:"basicPopulationModel"`
+}
+
+// LoadTables reads a JSON document that overrides some or all of the default tables.
+// Tables that aren't in the document keep their default values; tables that are
+// in the document are replaced in full.
+func LoadTables(r io.Reader) (*Tables_t, error) {
+	var doc tablesDocument_t
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: %w", err, ErrInvalidTable)
+	}
+	t := DefaultTables()
+	if doc.TightlyBoundClusterAge != nil {
+		t.TightlyBoundClusterAge = *doc.TightlyBoundClusterAge
+	}
+	if doc.LooselyBoundClusterAge != nil {
+		t.LooselyBoundClusterAge = *doc.LooselyBoundClusterAge
+	}
+	if doc.ClusterEvaporation != nil {
+		t.ClusterEvaporation = *doc.ClusterEvaporation
+	}
+	if doc.StellarAssociation != nil {
+		t.StellarAssociation = *doc.StellarAssociation
+	}
+	if doc.BasicPopulationModel != nil {
+		t.BasicPopulationModel = *doc.BasicPopulationModel
+	}
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	t.BasicPopulationModel.CombinedDensity = t.BasicPopulationModel.combinedDensity()
+	return t, nil
+}
+
+// Validate returns an error if any of the tables is invalid.
+func (t *Tables_t) Validate() error {
+	for _, err := range []error{
+		t.TightlyBoundClusterAge.Validate(),
+		t.LooselyBoundClusterAge.Validate(),
+		t.ClusterEvaporation.Validate(),
+		t.StellarAssociation.Validate(),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	if t.TightlyBoundClusterAge.Dice == nil || t.LooselyBoundClusterAge.Dice == nil {
+		return fmt.Errorf("cluster age tables must have dice: %w", ErrInvalidTable)
+	} else if t.StellarAssociation.Dice == nil {
+		return fmt.Errorf("stellar association table must have dice: %w", ErrInvalidTable)
+	}
+	for n, row := range t.StellarAssociation.Rows {
+		if row.Outcome.Radius == nil || row.Outcome.Members == nil {
+			return fmt.Errorf("stellar association row %d must have radius and members dice: %w", n+1, ErrInvalidTable)
+		}
+	}
+	return nil
+}
+
+// clone returns a copy of the tables that doesn't share rows or dice with the original.
+func (t *Tables_t) clone() *Tables_t {
+	nt := &Tables_t{
+		TightlyBoundClusterAge: t.TightlyBoundClusterAge.clone(),
+		LooselyBoundClusterAge: t.LooselyBoundClusterAge.clone(),
+		ClusterEvaporation:     t.ClusterEvaporation.clone(),
+		StellarAssociation:     t.StellarAssociation.clone(),
+		BasicPopulationModel:   t.BasicPopulationModel,
+	}
+	for n, row := range nt.StellarAssociation.Rows {
+		nt.StellarAssociation.Rows[n].Outcome.Radius = row.Outcome.Radius.clone()
+		nt.StellarAssociation.Rows[n].Outcome.Members = row.Outcome.Members.clone()
+	}
+	return nt
+}
+
+// builtinTables returns the tables loaded from the embedded data files.
+// The result is shared and must not be modified.
+func builtinTables() *Tables_t {
+	defaultTablesOnce.Do(func() {
+		t := &Tables_t{}
+		for _, file := range []struct {
+			name  string
+			table any
+		}{
+			{name: "tables/cluster_age_tightly_bound.json", table: &t.TightlyBoundClusterAge},
+			{name: "tables/cluster_age_loosely_bound.json", table: &t.LooselyBoundClusterAge},
+			{name: "tables/cluster_evaporation.json", table: &t.ClusterEvaporation},
+			{name: "tables/stellar_association.json", table: &t.StellarAssociation},
+			{name: "tables/basic_population_model.json", table: &t.BasicPopulationModel},
+		} {
+			data, err := tablesFS.ReadFile(file.name)
+			if err != nil {
+				panic(err)
+			}
+			dec := json.NewDecoder(bytes.NewReader(data))
+			dec.DisallowUnknownFields()
+			if err := dec.Decode(file.table); err != nil {
+				panic(fmt.Sprintf("aow: %s: %v", file.name, err))
+			}
+		}
+		if err := t.Validate(); err != nil {
+			panic(err)
+		}
+		t.BasicPopulationModel.CombinedDensity = t.BasicPopulationModel.combinedDensity()
+		defaultTables = t
+	})
+	return defaultTables
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2024 Michael D Henderson. All rights reserved.
+
+package aow_test
+
+import (
+	"errors"
+	"github.com/mdhender/aow"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"testing"
+)
+
+func TestDefaultTables(t *testing.T) {
+	tables := aow.DefaultTables()
+	for _, tc := range []struct {
+		name     string
+		table    *aow.RangeTable_t[aow.Range_t]
+		roll     int
+		expected aow.Range_t
+	}{
+		{"tightly bound", &tables.TightlyBoundClusterAge, 1, aow.Range_t{Min: 0.0, Max: 0.1}},
+		{"tightly bound", &tables.TightlyBoundClusterAge, 20, aow.Range_t{Min: 0.9, Max: 1.0}},
+		{"tightly bound", &tables.TightlyBoundClusterAge, 45, aow.Range_t{Min: 1.0, Max: 3.0}},
+		{"tightly bound", &tables.TightlyBoundClusterAge, 100, aow.Range_t{Min: 3.0, Max: 8.0}},
+		{"loosely bound", &tables.LooselyBoundClusterAge, 21, aow.Range_t{Min: 0.0, Max: 0.1}},
+		{"loosely bound", &tables.LooselyBoundClusterAge, 22, aow.Range_t{Min: 0.1, Max: 0.2}},
+		{"loosely bound", &tables.LooselyBoundClusterAge, 100, aow.Range_t{Min: 0.9, Max: 1.0}},
+	} {
+		if result := tc.table.Lookup(tc.roll); result != tc.expected {
+			t.Errorf("%s: Lookup(%d) = %+v, want %+v", tc.name, tc.roll, result, tc.expected)
+		}
+	}
+	for _, tc := range []struct {
+		roll     int
+		expected aow.ClusterZones_t
+	}{
+		{0, aow.ClusterZones_t{Core: 1.00}},
+		{4, aow.ClusterZones_t{Core: 0.41, Tidal: 0.41, Halo: 0.15}},
+		{25, aow.ClusterZones_t{Core: 0.11, Tidal: 0.27, Halo: 0.30}},
+	} {
+		if result := tables.ClusterEvaporation.Lookup(tc.roll); result != tc.expected {
+			t.Errorf("evaporation: Lookup(%d) = %+v, want %+v", tc.roll, result, tc.expected)
+		}
+	}
+
+	pm := aow.BasicPopulationModelTable()
+	if pm.YoungPopulationI.Density != 0.0344 || pm.HaloPopulationII.BaseAge != 9.5 {
+		t.Errorf("BasicPopulationModelTable() = %+v, want the values from the book", pm)
+	}
+	if math.Abs(pm.CombinedDensity-0.081129) > 0.000_001 {
+		t.Errorf("BasicPopulationModelTable() combined density = %f, want 0.081129", pm.CombinedDensity)
+	}
+
+	// callers get a copy they can change
+	tables.TightlyBoundClusterAge.Rows[0].Outcome.Max = 99
+	if aow.DefaultTables().TightlyBoundClusterAge.Rows[0].Outcome.Max != 0.1 {
+		t.Errorf("DefaultTables() shares rows between copies")
+	}
+}
+
+func TestRangeTable_Roll(t *testing.T) {
+	p := aow.NewPRNG(rand.NewPCG(0xcafe, 0xcafe)) // Use a fixed seed for reproducibility
+	table := aow.RangeTable_t[string]{
+		Name: "test",
+		Dice: aow.MustParseDice("3d6"),
+		Rows: []aow.RangeRow_t[string]{{Max: 10, Outcome: "low"}, {Max: 18, Outcome: "high"}},
+	}
+	if err := table.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	for n := 0; n < 100; n++ {
+		if result := table.Roll(p, -10); result != "low" {
+			t.Fatalf("Roll(-10) = %q, want %q", result, "low")
+		}
+		if result := table.Roll(p, +10); result != "high" {
+			t.Fatalf("Roll(+10) = %q, want %q", result, "high")
+		}
+	}
+}
+
+func TestLoadTables(t *testing.T) {
+	tables, err := aow.LoadTables(strings.NewReader(`{
+		"tightlyBoundClusterAge": {"name": "old", "dice": "d6", "rows": [{"max": 6, "outcome": {"min": 7, "max": 7}}]},
+		"looselyBoundClusterAge": {"name": "old", "dice": "d6", "rows": [{"max": 6, "outcome": {"min": 7, "max": 7}}]},
+		"basicPopulationModel": {"youngPopulationI": {"density": 0.0688, "baseAge": 0.0, "ageRange": 2.0}}
+	}`))
+	if err != nil {
+		t.Fatalf("LoadTables() error = %v", err)
+	}
+	if len(tables.ClusterEvaporation.Rows) != len(aow.DefaultTables().ClusterEvaporation.Rows) {
+		t.Errorf("LoadTables() replaced the evaporation table")
+	}
+
+	g, err := aow.New(100, rand.NewPCG(0xcafe, 0xcafe), aow.SurveyCatalog, aow.WithTables(tables))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if result := g.PopulationModel().YoungPopulationI.Density; result != 0.0688 {
+		t.Errorf("New() young population I density = %f, want 0.0688", result)
+	}
+	// the population model is replaced in full, so the other populations are empty
+	if result := g.PopulationModel().CombinedDensity; math.Abs(result-0.0688) > 0.000_001 {
+		t.Errorf("New() combined density = %f, want %f", result, 0.0688)
+	}
+	cluster, err := g.OpenCluster(aow.Coordinates{})
+	if err != nil {
+		t.Fatalf("OpenCluster() error = %v", err)
+	}
+	for _, ss := range cluster.Catalog.StarSystems {
+		if ss.Age < 0.95*7 || ss.Age > 1.051*7 || ss.Population != aow.OldPopulationI {
+			t.Errorf("OpenCluster() system age %f population %v, want about 7 and old population I", ss.Age, ss.Population)
+		}
+	}
+}
+
+func TestLoadTables_Errors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		doc  string
+	}{
+		{"unknown table", `{"stellarMass": {}}`},
+		{"no rows", `{"clusterEvaporation": {"name": "empty", "rows": []}}`},
+		{"rows not merged", `{"clusterEvaporation": {"name": "x"}}`},
+		{"out of order", `{"clusterEvaporation": {"name": "order", "rows": [{"max": 2}, {"max": 1}]}}`},
+		{"dice past last row", `{"looselyBoundClusterAge": {"name": "short", "dice": "2d6", "rows": [{"max": 6}]}}`},
+		{"no dice", `{"looselyBoundClusterAge": {"name": "dice", "rows": [{"max": 6}]}}`},
+	} {
+		if _, err := aow.LoadTables(strings.NewReader(tc.doc)); !errors.Is(err, aow.ErrInvalidTable) {
+			t.Errorf("%s: LoadTables() error = %v, want %v", tc.name, err, aow.ErrInvalidTable)
+		}
+	}
+	// a failed load doesn't change the defaults
+	if _, err := aow.LoadTables(strings.NewReader(`{"tightlyBoundClusterAge": {"name": "d6", "dice": "d6", "rows": [{"max": 6}]}, "looselyBoundClusterAge": {"name": "2d6", "dice": "2d6", "rows": [{"max": 6}]}}`)); err == nil {
+		t.Errorf("LoadTables() error = nil, want error")
+	}
+	if defaults := aow.DefaultTables(); defaults.TightlyBoundClusterAge.Dice.String() != "1d100" || defaults.LooselyBoundClusterAge.Dice.String() != "1d100" {
+		t.Errorf("LoadTables() changed the default dice to %s %s", defaults.TightlyBoundClusterAge.Dice, defaults.LooselyBoundClusterAge.Dice)
+	}
+	if _, err := aow.LoadTables(strings.NewReader(`{"looselyBoundClusterAge": {"name": "dice", "dice": "2x6", "rows": [{"max": 6}]}}`)); !errors.Is(err, aow.ErrInvalidDiceExpression) {
+		t.Errorf("bad dice: LoadTables() error = %v, want %v", err, aow.ErrInvalidDiceExpression)
+	}
+	if _, err := aow.New(100, rand.NewPCG(0xcafe, 0xcafe), aow.SurveyCatalog, aow.WithTables(nil)); err != aow.ErrTablesNil {
+		t.Errorf("New() error = %v, want %v", err, aow.ErrTablesNil)
+	}
+}